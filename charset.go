@@ -0,0 +1,50 @@
+package gordom
+
+import (
+	"bufio"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+)
+
+const charsetPeekSize = 1024
+
+// detectCharset returns the encoding declared by a BOM, the content type or meta tags in the head of a page.
+// Unlike charset.DetermineEncoding, it falls back to UTF-8 instead of windows-1252.
+func detectCharset(head []byte, contentType string) string {
+	_, name, certain := charset.DetermineEncoding(head, contentType)
+	if certain || name != "windows-1252" {
+		return name
+	}
+	// windows-1252 is the fallback of ASCII content as well, so check if it's declared by appending a UTF-8 char:
+	// the fallback of the probe is UTF-8 while a declared charset stays the same
+	if len(head) > charsetPeekSize-3 {
+		head = head[:charsetPeekSize-3]
+	}
+	probe := append(append([]byte{}, head...), "é "...)
+	if _, probeName, _ := charset.DetermineEncoding(probe, contentType); probeName == "utf-8" {
+		return "utf-8"
+	}
+	return name
+}
+
+// utf8Reader converts the page to UTF-8 and removes a BOM.
+// The charset set by WithCharset takes precedence over detection.
+func (d *Decoder) utf8Reader(reader io.Reader, contentType string) (io.Reader, error) {
+	name := d.opts.charset
+	buffered := bufio.NewReaderSize(reader, charsetPeekSize)
+	if name == "" {
+		head, err := buffered.Peek(charsetPeekSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		name = detectCharset(head, contentType)
+	}
+	e, _ := charset.Lookup(name)
+	if e == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCharset, name)
+	}
+	return transform.NewReader(buffered, unicode.BOMOverride(e.NewDecoder())), nil
+}
@@ -0,0 +1,85 @@
+package gordom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// "Привет" in windows-1251
+const cp1251Text = "\xcf\xf0\xe8\xe2\xe5\xf2"
+
+func charsetHtml(head string, text string) string {
+	return fmt.Sprintf(`<html><head>%s</head><body><div id="text">%s</div></body></html>`, head, text)
+}
+
+func TestParseCharset(t *testing.T) {
+	cases := []struct {
+		name, html, expected string
+	}{
+		{"meta charset", charsetHtml(`<meta charset="windows-1251">`, cp1251Text), "Привет"},
+		{"meta http-equiv", charsetHtml(`<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">`, "\x93\xfa\x96\x7b"), "日本"},
+		{"latin1", charsetHtml(`<meta charset="iso-8859-1">`, "caf\xe9"), "café"},
+		{"utf-8 bom", "\xef\xbb\xbf" + charsetHtml("", "café"), "café"},
+		{"utf-16 bom", "\xff\xfe" + utf16le(charsetHtml("", "café")), "café"},
+		{"utf-8", charsetHtml("", "café"), "café"},
+		{"ascii head", charsetHtml(strings.Repeat(" ", 2000), "café"), "café"},
+	}
+	for _, c := range cases {
+		text := &Text{}
+		assert.Nil(t, Parse(c.html, text), c.name)
+		assert.Equal(t, c.expected, text.Text, c.name)
+	}
+}
+
+func utf16le(s string) string {
+	b := &strings.Builder{}
+	for _, r := range s {
+		b.WriteByte(byte(r))
+		b.WriteByte(byte(r >> 8))
+	}
+	return b.String()
+}
+
+func TestParseCharsetOption(t *testing.T) {
+	text := &Text{}
+	assert.Nil(t, Parse(charsetHtml("", cp1251Text), text, WithCharset("windows-1251")))
+	assert.Equal(t, "Привет", text.Text)
+
+	assert.Nil(t, Parse(charsetHtml(`<meta charset="utf-8">`, cp1251Text), text, WithCharset("cp1251")))
+	assert.Equal(t, "Привет", text.Text)
+
+	err := Parse(charsetHtml("", "text"), text, WithCharset("unknown"))
+	assert.True(t, errors.Is(err, ErrUnknownCharset))
+}
+
+func TestParseFromURLCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		fmt.Fprint(w, charsetHtml(`<meta charset="utf-8">`, cp1251Text))
+	}))
+	defer server.Close()
+
+	text := &Text{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL, text))
+	assert.Equal(t, "Привет", text.Text)
+}
+
+func TestDecodeEachCharset(t *testing.T) {
+	html := charsetHtml(`<meta charset="windows-1251">`, cp1251Text)
+	var texts []string
+	type Item struct {
+		Text string
+	}
+	err := DecodeEach(strings.NewReader(html), "#text", func(item *Item) error {
+		texts = append(texts, item.Text)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Привет"}, texts)
+}
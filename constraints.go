@@ -0,0 +1,100 @@
+package gordom
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"reflect"
+	"strconv"
+)
+
+var (
+	ErrRequired   = errors.New("required node is not found")
+	ErrCount      = errors.New("unexpected number of nodes")
+	ErrInvalidTag = errors.New("invalid tag value")
+)
+
+var countTags = []string{"count", "min", "max"}
+
+type countLimit struct {
+	key   string
+	limit int
+}
+
+func (c countLimit) check(count int) error {
+	if c.key == "count" && count != c.limit || c.key == "min" && count < c.limit || c.key == "max" && count > c.limit {
+		return fmt.Errorf("%w: found %d, %s is %d", ErrCount, count, c.key, c.limit)
+	}
+	return nil
+}
+
+func boolTag(typeField reflect.StructField, key string) (bool, error) {
+	tag, has := typeField.Tag.Lookup(key)
+	if !has {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(tag)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s:%q", ErrInvalidTag, key, tag)
+	}
+	return value, nil
+}
+
+func countLimits(typeField reflect.StructField) ([]countLimit, error) {
+	var limits []countLimit
+	for _, key := range countTags {
+		tag, has := typeField.Tag.Lookup(key)
+		if !has {
+			continue
+		}
+		limit, err := strconv.Atoi(tag)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("%w: %s:%q", ErrInvalidTag, key, tag)
+		}
+		limits = append(limits, countLimit{key: key, limit: limit})
+	}
+	return limits, nil
+}
+
+func checkCount(count int, field *fieldPlan) error {
+	for _, limit := range field.limits {
+		if err := limit.check(count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRequired(found bool, field *fieldPlan) error {
+	if field.required && !found {
+		return ErrRequired
+	}
+	return nil
+}
+
+func findChecked(n *node.Node, field *fieldPlan) (*node.Node, error) {
+	found := findOne(n, field)
+	if err := checkRequired(found != nil, field); err != nil {
+		return nil, err
+	}
+	if len(field.limits) > 0 {
+		if err := checkCount(len(findMany(n, field)), field); err != nil {
+			return nil, err
+		}
+	}
+	if found == nil {
+		return defaultNode(field), nil
+	}
+	return found, nil
+}
+
+func findManyChecked(n *node.Node, field *fieldPlan) ([]*node.Node, error) {
+	found := findMany(n, field)
+	if err := checkRequired(len(found) > 0, field); err != nil {
+		return nil, err
+	}
+	if err := checkCount(len(found), field); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
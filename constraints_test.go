@@ -0,0 +1,88 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const constraintsHtml = `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<h1 id="title">Title</h1>
+		<span class="tag">go</span>
+		<span class="tag">html</span>
+	</body>
+	`
+
+type RequiredFound struct {
+	Title string   `$:"#title" required:"true" count:"1"`
+	Tags  []string `$:".tag" required:"true" min:"1" max:"2"`
+}
+
+func TestParseRequiredFound(t *testing.T) {
+	r := &RequiredFound{}
+	err := Parse(constraintsHtml, r)
+	assert.Nil(t, err)
+	assert.Equal(t, "Title", r.Title)
+	assert.Equal(t, []string{"go", "html"}, r.Tags)
+}
+
+type RequiredNotFound struct {
+	Price int `$:"#price" required:"true"`
+}
+
+func TestParseRequiredNotFound(t *testing.T) {
+	err := Parse(constraintsHtml, &RequiredNotFound{})
+	assert.True(t, errors.Is(err, ErrRequired))
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Price", fieldErr.Path)
+}
+
+type RequiredStruct struct {
+	Image *Image `$:"img" required:"true"`
+}
+
+func TestParseRequiredStructNotFound(t *testing.T) {
+	err := Parse(constraintsHtml, &RequiredStruct{})
+	assert.True(t, errors.Is(err, ErrRequired))
+}
+
+type RequiredSlice struct {
+	Images []Image `$:"img" required:"true"`
+}
+
+func TestParseRequiredSliceNotFound(t *testing.T) {
+	err := Parse(constraintsHtml, &RequiredSlice{})
+	assert.True(t, errors.Is(err, ErrRequired))
+}
+
+type CountMismatch struct {
+	Tag string `$:".tag" count:"1"`
+}
+
+type MaxMismatch struct {
+	Tags []string `$:".tag" max:"1"`
+}
+
+type MinMismatch struct {
+	Tags []string `$:".tag" min:"3"`
+}
+
+func TestParseCountMismatch(t *testing.T) {
+	assert.True(t, errors.Is(Parse(constraintsHtml, &CountMismatch{}), ErrCount))
+	assert.True(t, errors.Is(Parse(constraintsHtml, &MaxMismatch{}), ErrCount))
+	assert.True(t, errors.Is(Parse(constraintsHtml, &MinMismatch{}), ErrCount))
+}
+
+type InvalidTag struct {
+	Title string `$:"#title" required:"yes please"`
+}
+
+func TestParseInvalidTag(t *testing.T) {
+	assert.True(t, errors.Is(Parse(constraintsHtml, &InvalidTag{}), ErrInvalidTag))
+}
@@ -0,0 +1,196 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+}
+
+var (
+	trueValues  = []string{"true", "1", "yes", "y", "on"}
+	falseValues = []string{"false", "0", "no", "n", "off", ""}
+)
+
+// isConverted reports whether the type has a built-in conversion. Bool types implementing
+// an unmarshaler interface decode themselves.
+func isConverted(t reflect.Type) bool {
+	return t == timeType || t == durationType || t == urlType || t.Kind() == reflect.Bool && !isUnmarshaler(t)
+}
+
+func (p *parser) parseConverted(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	return p.convert(found, field, valueField)
+}
+
+func (p *parser) convert(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	switch valueField.Type() {
+	case timeType:
+		return p.parseTime(n, field, valueField)
+	case durationType:
+		return p.parseDuration(n, field, valueField)
+	case urlType:
+		return p.parseURL(n, field, valueField)
+	}
+	return p.parseBool(n, field, valueField)
+}
+
+// Converter converts a field value to a value of the type registered by WithConverter
+type Converter func(value string) (interface{}, error)
+
+func (p *parser) parseCustom(node *node.Node, field *fieldPlan, valueField reflect.Value, converter Converter) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	return p.customConvert(found, field, valueField, converter)
+}
+
+func (p *parser) customConvert(n *node.Node, field *fieldPlan, valueField reflect.Value, converter Converter) error {
+	value, err := p.getValue(n, field)
+	if err != nil {
+		return err
+	}
+	converted, err := converter(value)
+	if err != nil {
+		return newValueError(value, err)
+	}
+	convertedValue := reflect.ValueOf(converted)
+	if !convertedValue.IsValid() {
+		return nil
+	}
+	if !convertedValue.Type().ConvertibleTo(valueField.Type()) {
+		return newValueError(value, fmt.Errorf("converter returned %s instead of %s", convertedValue.Type(), valueField.Type()))
+	}
+	valueField.Set(convertedValue.Convert(valueField.Type()))
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPresent(n *node.Node, field *fieldPlan) bool {
+	for _, source := range field.sources {
+		if !isAttrSource(source) {
+			return true
+		}
+		if _, has := n.Attrs[strings.Trim(source, "[]")]; has {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseBool(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	if field.boolPresent {
+		valueField.SetBool(isPresent(n, field))
+		return nil
+	}
+	value, err := p.getValue(n, field)
+	if err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+	if field.hasTruthy {
+		valueField.SetBool(containsFold(field.truthy, value))
+		return nil
+	}
+	switch {
+	case containsFold(trueValues, value):
+		valueField.SetBool(true)
+	case containsFold(falseValues, value):
+		valueField.SetBool(false)
+	default:
+		return newValueError(value, &strconv.NumError{Func: "ParseBool", Num: value, Err: strconv.ErrSyntax})
+	}
+	return nil
+}
+
+func (p *parser) parseDuration(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	value, err := p.getValue(n, field)
+	if err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(value, " ", ""))
+	if err != nil {
+		return newValueError(value, err)
+	}
+	valueField.SetInt(int64(d))
+	return nil
+}
+
+func (p *parser) parseTime(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	value, err := p.getTimeValue(n, field)
+	if err != nil {
+		return err
+	}
+	layouts := timeLayouts
+	if len(field.layouts) > 0 {
+		layouts = field.layouts
+	}
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			valueField.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+	return newValueError(value, fmt.Errorf("time doesn't match layouts %q", layouts))
+}
+
+// getTimeValue takes datetime attribute of <time> node unless the value tag is set
+func (p *parser) getTimeValue(n *node.Node, field *fieldPlan) (string, error) {
+	if !field.hasValueTag {
+		if datetime, has := n.Attrs["datetime"]; has {
+			return datetime, nil
+		}
+	}
+	value, err := p.getValue(n, field)
+	return strings.TrimSpace(value), err
+}
+
+func (p *parser) parseURL(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	value, err := p.getValue(n, field)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return newValueError(value, err)
+	}
+	valueField.Set(reflect.ValueOf(*u))
+	return nil
+}
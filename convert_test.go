@@ -0,0 +1,105 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const convertHtml = `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<span class="yes">Yes</span>
+		<span class="stock">In stock</span>
+		<input class="checkbox" type="checkbox" checked>
+		<time class="published" datetime="2020-03-24T14:37:07Z">March 24</time>
+		<span class="date">24.03.2020</span>
+		<span class="iso">2020-03-24</span>
+		<span class="duration">1h 30m</span>
+		<a href="https://example.com/a?b=c">link</a>
+	</body>
+	`
+
+type Converted struct {
+	Yes          bool          `$:".yes"`
+	InStock      bool          `$:".stock" truthy:"In stock,Available"`
+	Checked      bool          `$:".checkbox" value:"[checked]" bool:"present"`
+	Disabled     bool          `$:".checkbox" value:"[disabled]" bool:"present"`
+	HasLink      bool          `$:"a" bool:"present"`
+	HasButton    bool          `$:"button" bool:"present"`
+	Published    time.Time     `$:".published"`
+	PublishedPtr *time.Time    `$:".published"`
+	Date         time.Time     `$:".date" layout:"2006-01-02|02.01.2006"`
+	ISO          time.Time     `$:".iso"`
+	NoDate       time.Time     `$:".no-date"`
+	Duration     time.Duration `$:".duration"`
+	URL          url.URL       `$:"a" value:"[href]"`
+	URLPtr       *url.URL      `$:"a" value:"[href]"`
+	NoURL        *url.URL      `$:"img" value:"[src]"`
+}
+
+func TestParseConverted(t *testing.T) {
+	c := &Converted{}
+	err := Parse(convertHtml, c)
+	assert.Nil(t, err)
+	assert.True(t, c.Yes)
+	assert.True(t, c.InStock)
+	assert.True(t, c.Checked)
+	assert.False(t, c.Disabled)
+	assert.True(t, c.HasLink)
+	assert.False(t, c.HasButton)
+	assert.Equal(t, time.Date(2020, 3, 24, 14, 37, 7, 0, time.UTC), c.Published)
+	assert.Equal(t, c.Published, *c.PublishedPtr)
+	assert.Equal(t, time.Date(2020, 3, 24, 0, 0, 0, 0, time.UTC), c.Date)
+	assert.Equal(t, c.Date, c.ISO)
+	assert.True(t, c.NoDate.IsZero())
+	assert.Equal(t, 90*time.Minute, c.Duration)
+	assert.Equal(t, "example.com", c.URL.Host)
+	assert.Equal(t, "c", c.URLPtr.Query().Get("b"))
+	assert.Nil(t, c.NoURL)
+}
+
+type InvalidBool struct {
+	Stock bool `$:".stock"`
+}
+
+type InvalidTime struct {
+	Date time.Time `$:".date" layout:"2006-01-02"`
+}
+
+func TestParseConvertedErrors(t *testing.T) {
+	assert.True(t, errors.Is(Parse(convertHtml, &InvalidBool{}), strconv.ErrSyntax))
+	err := Parse(convertHtml, &InvalidTime{})
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "24.03.2020", fieldErr.Value)
+}
+
+// Flag is true for a check mark
+type Flag bool
+
+func (f *Flag) UnmarshalText(text []byte) error {
+	*f = string(text) == "✓"
+	return nil
+}
+
+type Yes bool
+
+type NamedBools struct {
+	InStock Flag `$:".flag"`
+	Yes     Yes  `$:".yes"`
+}
+
+func TestParseNamedBool(t *testing.T) {
+	named := &NamedBools{}
+	err := Parse(`<html><body><span class="flag">✓</span><span class="yes">yes</span></body></html>`, named)
+	assert.Nil(t, err)
+	assert.Equal(t, Flag(true), named.InStock)
+	assert.Equal(t, Yes(true), named.Yes)
+}
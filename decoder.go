@@ -0,0 +1,147 @@
+package gordom
+
+import (
+	"context"
+	"github.com/lucky-libora/gordom/node"
+	"io"
+	"net/url"
+	"reflect"
+)
+
+// Decoder maps html to structs. It's configured by options once and is safe for concurrent use.
+type Decoder struct {
+	opts options
+}
+
+var defaultDecoder = NewDecoder()
+
+func NewDecoder(opts ...Option) *Decoder {
+	return &Decoder{
+		opts: newOptions(opts),
+	}
+}
+
+func decoderOf(opts []Option) *Decoder {
+	if len(opts) == 0 {
+		return defaultDecoder
+	}
+	return NewDecoder(opts...)
+}
+
+func (d *Decoder) newParser() *parser {
+	return &parser{
+		opts: d.opts,
+	}
+}
+
+// Compile builds and caches the decoding plan of the struct type, so schema errors like invalid tags
+// can be checked at startup. It accepts a struct or a pointer to struct.
+func (d *Decoder) Compile(v interface{}) error {
+	return compileType(v, d.opts.tags)
+}
+
+// Decode parses html and maps it to the struct. Non-UTF-8 html is converted to UTF-8 first,
+// the charset is detected by a BOM or meta tags.
+func (d *Decoder) Decode(reader io.Reader, ptr interface{}) error {
+	return d.decode(reader, "", ptr)
+}
+
+func (d *Decoder) decode(reader io.Reader, contentType string, ptr interface{}) error {
+	reader, err := d.utf8Reader(reader, contentType)
+	if err != nil {
+		return err
+	}
+	return d.DecodeDocument(node.ParseHtml(reader), ptr)
+}
+
+// DecodeDocument maps the body of the document to the struct. If the url of the document is unknown,
+// urls are resolved against the url set by WithURL. The document is changed only by WithAbsoluteURLs.
+func (d *Decoder) DecodeDocument(doc *node.Document, ptr interface{}) error {
+	if doc == nil || doc.Body == nil {
+		return ErrNilNode
+	}
+	p, err := d.documentParser(doc)
+	if err != nil {
+		return err
+	}
+	if d.opts.absoluteURLs {
+		doc.Head.ResolveURLs(p.base)
+		doc.Body.ResolveURLs(p.base)
+	}
+	return p.parseByType(doc.Body, ptr)
+}
+
+// DecodeNode maps the node to the struct. Urls are resolved against the url set by WithURL,
+// WithAbsoluteURLs rewrites urls of the node and its descendants.
+func (d *Decoder) DecodeNode(n *node.Node, ptr interface{}) error {
+	if n == nil {
+		return ErrNilNode
+	}
+	p := d.newParser()
+	base, err := d.optionURL()
+	if err != nil {
+		return err
+	}
+	p.base = base
+	if d.opts.absoluteURLs {
+		n.ResolveURLs(base)
+	}
+	return p.parseByType(n, ptr)
+}
+
+func (d *Decoder) optionURL() (*url.URL, error) {
+	if d.opts.url == "" {
+		return nil, nil
+	}
+	return url.Parse(d.opts.url)
+}
+
+// documentParser returns a parser which resolves urls against the base url of the document.
+// The document url is taken from WithURL if it's unknown, the document itself isn't changed.
+func (d *Decoder) documentParser(doc *node.Document) (*parser, error) {
+	p := d.newParser()
+	if doc.URL != nil {
+		p.base = doc.BaseURL()
+		return p, nil
+	}
+	u, err := d.optionURL()
+	if err != nil {
+		return nil, err
+	}
+	withURL := *doc
+	withURL.URL = u
+	p.base = withURL.BaseURL()
+	return p, nil
+}
+
+func (d *Decoder) DecodeURL(rawURL string, ptr interface{}) error {
+	return d.DecodeURLContext(context.Background(), rawURL, ptr)
+}
+
+// DecodeURLContext fetches the page and maps it to the struct. StatusError is returned for non-2xx statuses.
+// The charset of the page is also detected by the Content-Type header.
+// If the next page is set by the next tag or WithNextPage, next pages are fetched and merged to the struct.
+func (d *Decoder) DecodeURLContext(ctx context.Context, rawURL string, ptr interface{}) error {
+	doc, err := d.fetchDocument(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	f := d.newFetcher(ctx)
+	err = f.decodePage(doc, ptr)
+	if _, partial := err.(FieldErrors); err != nil && !partial {
+		return err
+	}
+	value := reflect.ValueOf(ptr).Elem()
+	plan, planErr := structPlanOf(value.Type(), d.opts.tags)
+	if planErr != nil {
+		return planErr
+	}
+	next, nextErr := d.nextPlan(plan)
+	if nextErr != nil {
+		return nextErr
+	}
+	if next == nil {
+		return err
+	}
+	return f.decodePages(doc, value, plan, next, err)
+}
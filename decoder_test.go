@@ -0,0 +1,127 @@
+package gordom
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Amount struct {
+	Amount   float64
+	Currency string
+}
+
+type DecoderProduct struct {
+	Name  string   `css:".name"`
+	Price Amount   `css:".price" from:"[data-price]"`
+	Tags  []string `css:".tag"`
+	Skip  string   `css:"-"`
+}
+
+const decoderHtml = `<body>
+<div class="name">  Lamp
+	desk  </div>
+<div class="price" data-price="12.5 EUR">12.50 €</div>
+<span class="tag">a</span><span class="tag">b</span>
+</body>`
+
+func parseAmount(value string) (interface{}, error) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid money %q", value)
+	}
+	m := Amount{Currency: parts[1]}
+	_, err := fmt.Sscan(parts[0], &m.Amount)
+	return m, err
+}
+
+func TestDecoder(t *testing.T) {
+	decoder := NewDecoder(
+		WithSelectorTag("css"),
+		WithValueTag("from"),
+		WithTextNormalizer(NormalizeSpace),
+		WithConverter(Amount{}, parseAmount),
+	)
+	product := DecoderProduct{}
+	err := decoder.Decode(strings.NewReader(decoderHtml), &product)
+	assert.Nil(t, err)
+	assert.Equal(t, "Lamp desk", product.Name)
+	assert.Equal(t, Amount{Amount: 12.5, Currency: "EUR"}, product.Price)
+	assert.Equal(t, []string{"a", "b"}, product.Tags)
+	assert.Equal(t, "", product.Skip)
+}
+
+type PointerMoney struct {
+	Prices []*Amount `$:".price" value:"[data-price]"`
+}
+
+const pricesHtml = `<body><i class="price" data-price="1 USD"></i><i class="price" data-price="x"></i></body>`
+
+func TestDecoderConverterError(t *testing.T) {
+	decoder := NewDecoder(WithConverter(Amount{}, parseAmount))
+	prices := PointerMoney{}
+	err := decoder.Decode(strings.NewReader(pricesHtml), &prices)
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Prices[1]", fieldErr.Path)
+	assert.Equal(t, "x", fieldErr.Value)
+
+	decoder = NewDecoder(WithConverter(Amount{}, parseAmount), WithLenient())
+	err = decoder.Decode(strings.NewReader(pricesHtml), &prices)
+	assert.NotNil(t, err)
+	assert.Equal(t, &Amount{Amount: 1, Currency: "USD"}, prices.Prices[0])
+}
+
+type TaggedCount struct {
+	Count int `css:".price" from:"[data-price]"`
+}
+
+type PriceCount struct {
+	Count int `$:".price" value:"[data-price]"`
+}
+
+func TestDecoderErrorTag(t *testing.T) {
+	err := NewDecoder(WithSelectorTag("css"), WithValueTag("from")).Decode(strings.NewReader(pricesHtml), &TaggedCount{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `field Count (css:".price") can't be set from "1 USD"`)
+
+	err = Parse(pricesHtml, &PriceCount{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `field Count ($:".price")`)
+}
+
+func TestDecoderConverterType(t *testing.T) {
+	decoder := NewDecoder(WithConverter(Amount{}, func(value string) (interface{}, error) {
+		return value, nil
+	}))
+	product := struct {
+		Price Amount `$:".price"`
+	}{}
+	err := decoder.Decode(strings.NewReader(decoderHtml), &product)
+	assert.NotNil(t, err)
+}
+
+func TestDecoderTagsPlans(t *testing.T) {
+	plan, err := structPlanOf(reflect.TypeOf(DecoderProduct{}), defaultTags)
+	assert.Nil(t, err)
+	assert.Equal(t, "", plan.fields[0].selector)
+
+	tags := tagNames{selector: "css", value: "from"}
+	assert.Nil(t, NewDecoder(WithSelectorTag("css"), WithValueTag("from")).Compile(&DecoderProduct{}))
+	plan, err = structPlanOf(reflect.TypeOf(DecoderProduct{}), tags)
+	assert.Nil(t, err)
+	assert.Equal(t, ".name", plan.fields[0].selector)
+	assert.Equal(t, 3, len(plan.fields))
+}
+
+func TestDecodeNilNode(t *testing.T) {
+	assert.True(t, errors.Is(NewDecoder().DecodeNode(nil, &Struct{}), ErrNilNode))
+	assert.True(t, errors.Is(NewDecoder().Decode(strings.NewReader("<div></div>"), &Struct{}), ErrNilNode))
+}
+
+func TestNormalizeSpace(t *testing.T) {
+	assert.Equal(t, "a b c", NormalizeSpace(" a \n\t b  c "))
+}
@@ -0,0 +1,48 @@
+package gordom
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Defaults struct {
+	Comments    int      `$:".comments" default:"0"`
+	Empty       int      `$:".empty" default:"0"`
+	Author      string   `$:".author" default:"anonymous"`
+	Score       *float64 `$:".score" default:"1.5"`
+	NoScore     *float64 `$:".score"`
+	Rating      Rating   `$:".rating" default:"5 stars"`
+	Price       Money    `$:".price" default:"0"`
+	Link        string   `$:"a" value:"[href]" default:"#"`
+	Counters    []int    `$:".counter" default:"0"`
+	ExistingInt int      `$:".existing" default:"0"`
+}
+
+func TestParseDefaults(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<span class="empty"></span>
+		<a>link</a>
+		<span class="counter">1</span>
+		<span class="counter"></span>
+		<span class="existing">7</span>
+	</body>
+	`
+	d := &Defaults{}
+	err := Parse(html, d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d.Comments)
+	assert.Equal(t, 0, d.Empty)
+	assert.Equal(t, "anonymous", d.Author)
+	assert.Equal(t, 1.5, *d.Score)
+	assert.Nil(t, d.NoScore)
+	assert.Equal(t, Rating(5), d.Rating)
+	assert.Equal(t, "0", d.Price.Amount)
+	assert.Equal(t, "#", d.Link)
+	assert.Equal(t, []int{1, 0}, d.Counters)
+	assert.Equal(t, 7, d.ExistingInt)
+}
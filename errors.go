@@ -0,0 +1,136 @@
+package gordom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotPointer      = errors.New("non-nil pointer should be passed")
+	ErrNotStruct       = errors.New("pointer to struct should be passed")
+	ErrNilNode         = errors.New("node should not be nil")
+	ErrNotFunc         = errors.New("func(item *T) error should be passed")
+	ErrInvalidSelector = errors.New("invalid selector")
+	ErrUnknownCharset  = errors.New("unknown charset")
+)
+
+// FieldError is returned when a found value can't be set to a struct field.
+// Path is the Go field path from the root struct, e.g. Info.Commits or Files[2].Name.
+type FieldError struct {
+	Path     string
+	Selector string
+	Value    string
+	Err      error
+	named    bool
+	tag      string
+}
+
+func (e *FieldError) Error() string {
+	tag := e.tag
+	if tag == "" {
+		tag = defaultTags.selector
+	}
+	return fmt.Sprintf("field %s (%s:%q) can't be set from %q: %v", e.Path, tag, e.Selector, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is returned in lenient mode and contains an error for every field which can't be set
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d fields can't be set: %s", len(e), strings.Join(messages, "; "))
+}
+
+func (e FieldErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e FieldErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendErrors(errs FieldErrors, err error) FieldErrors {
+	if fieldErrs, ok := err.(FieldErrors); ok {
+		return append(errs, fieldErrs...)
+	}
+	return append(errs, toFieldError(err))
+}
+
+func newValueError(value string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Value: value, Err: err}
+}
+
+func toFieldError(err error) *FieldError {
+	fieldErr, ok := err.(*FieldError)
+	if !ok {
+		fieldErr = &FieldError{Err: err}
+	}
+	return fieldErr
+}
+
+// withField adds the field name to the error path. The selector of the innermost field is kept,
+// tag is the name of the tag it's taken from.
+func withField(err error, name string, tag string, selector string) error {
+	if errs, ok := err.(FieldErrors); ok {
+		for _, fieldErr := range errs {
+			withField(fieldErr, name, tag, selector)
+		}
+		return errs
+	}
+	fieldErr := toFieldError(err)
+	if !fieldErr.named {
+		fieldErr.Selector = selector
+		fieldErr.tag = tag
+		fieldErr.named = true
+	}
+	fieldErr.Path = joinPath(name, fieldErr.Path)
+	return fieldErr
+}
+
+func withIndex(err error, i int) error {
+	return withElem(err, fmt.Sprintf("[%d]", i))
+}
+
+func withKey(err error, key string) error {
+	return withElem(err, fmt.Sprintf("[%q]", key))
+}
+
+func withElem(err error, elem string) error {
+	if errs, ok := err.(FieldErrors); ok {
+		for _, fieldErr := range errs {
+			withElem(fieldErr, elem)
+		}
+		return errs
+	}
+	fieldErr := toFieldError(err)
+	fieldErr.Path = joinPath(elem, fieldErr.Path)
+	return fieldErr
+}
+
+func joinPath(parent string, path string) string {
+	if path == "" || strings.HasPrefix(path, "[") {
+		return parent + path
+	}
+	return parent + "." + path
+}
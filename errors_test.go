@@ -0,0 +1,141 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+type ErrorInfo struct {
+	Commits int8 `$:".commits .num"`
+}
+
+type ErrorProject struct {
+	Info ErrorInfo `$:".info"`
+}
+
+type ErrorFile struct {
+	Size int `$:".size"`
+}
+
+type ErrorFiles struct {
+	Files []ErrorFile `$:".file"`
+	Ints  []int       `$:".int"`
+}
+
+func TestFieldErrorPath(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div class="info">
+			<div class="commits"><span class="num">many</span></div>
+		</div>
+	</body>
+	`
+	err := Parse(html, &ErrorProject{})
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Info.Commits", fieldErr.Path)
+	assert.Equal(t, ".commits .num", fieldErr.Selector)
+	assert.Equal(t, "many", fieldErr.Value)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+func TestFieldErrorSlicePath(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div class="file"><i class="size">1</i></div>
+		<div class="file"><i class="size">2kb</i></div>
+	</body>
+	`
+	err := Parse(html, &ErrorFiles{})
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Files[1].Size", fieldErr.Path)
+	assert.Equal(t, ".size", fieldErr.Selector)
+	assert.Equal(t, "2kb", fieldErr.Value)
+}
+
+func TestFieldErrorScalarSlicePath(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<i class="int">1</i>
+		<i class="int">two</i>
+	</body>
+	`
+	err := Parse(html, &ErrorFiles{})
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Ints[1]", fieldErr.Path)
+	assert.Equal(t, ".int", fieldErr.Selector)
+	assert.Equal(t, "two", fieldErr.Value)
+}
+
+func TestSchemaErrors(t *testing.T) {
+	html := "<html><body>Test</body></html>"
+	text := ""
+	assert.True(t, errors.Is(Parse(html, &text), ErrNotStruct))
+	assert.True(t, errors.Is(Parse(html, Text{}), ErrNotPointer))
+	assert.True(t, errors.Is(Parse(html, (*Text)(nil)), ErrNotPointer))
+}
+
+type LenientProduct struct {
+	Name  string `$:".name"`
+	Price int    `$:".price"`
+}
+
+type LenientListing struct {
+	Title    string           `$:"h1" required:"true"`
+	Products []LenientProduct `$:".product"`
+	Count    int              `$:".count"`
+}
+
+func TestParseLenient(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div class="product"><span class="name">A</span><span class="price">1</span></div>
+		<div class="product"><span class="name">B</span><span class="price">n/a</span></div>
+		<div class="product"><span class="name">C</span><span class="price">3</span></div>
+		<span class="count">3</span>
+	</body>
+	`
+	l := &LenientListing{}
+	err := Parse(html, l, WithLenient())
+	assert.Equal(t, 3, len(l.Products))
+	assert.Equal(t, "B", l.Products[1].Name)
+	assert.Equal(t, 3, l.Products[2].Price)
+	assert.Equal(t, 3, l.Count)
+
+	errs := FieldErrors{}
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "Title", errs[0].Path)
+	assert.Equal(t, "Products[1].Price", errs[1].Path)
+	assert.Equal(t, "n/a", errs[1].Value)
+	assert.True(t, errors.Is(err, ErrRequired))
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Title", fieldErr.Path)
+
+	strict := &LenientListing{}
+	err = Parse(html, strict)
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Empty(t, strict.Products)
+}
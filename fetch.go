@@ -0,0 +1,95 @@
+package gordom
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// StatusError is returned when a page is fetched with a non-2xx status.
+// The body of the response is already closed.
+type StatusError struct {
+	URL      string
+	Response *http.Response
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s of %s", e.Response.Status, e.URL)
+}
+
+func (d *Decoder) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range d.opts.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// fetch gets the page and returns the response with 2xx status. The caller should close the body.
+func (d *Decoder) fetch(ctx context.Context, url string) (*http.Response, error) {
+	req, err := d.newRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.opts.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &StatusError{URL: url, Response: resp}
+	}
+	return resp, nil
+}
+
+// fetchDocument fetches and parses the page. The url of the document is the url of the response,
+// it may differ from the requested one after redirects.
+func (d *Decoder) fetchDocument(ctx context.Context, rawURL string) (*node.Document, error) {
+	html, pageURL, err := d.fetchPage(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return d.parsePage(html, pageURL), nil
+}
+
+// fetchPage fetches the page and returns its html converted to UTF-8 and the url of the response
+func (d *Decoder) fetchPage(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	resp, err := d.fetch(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	reader, err := d.utf8Reader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+	html, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return html, resp.Request.URL, nil
+}
+
+// parsePage parses the fetched html to a new document
+func (d *Decoder) parsePage(html []byte, pageURL *url.URL) *node.Document {
+	doc := node.ParseHtml(bytes.NewReader(html))
+	doc.URL = pageURL
+	if d.opts.absoluteURLs {
+		doc.ResolveURLs()
+	}
+	return doc
+}
+
+func basicAuth(username string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
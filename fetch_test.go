@@ -0,0 +1,98 @@
+package gordom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseFromURLContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><body><div id="text">%s|%s|%s</div></body></html>`,
+			r.Header.Get("User-Agent"), r.Header.Get("Authorization"), r.Header.Get("X-Test"))
+	}))
+	defer server.Close()
+
+	text := &Text{}
+	err := ParseFromURLContext(context.Background(), server.URL, text,
+		WithUserAgent("gordom-test"),
+		WithBearerToken("token"),
+		WithHeader("X-Test", "1"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "gordom-test|Bearer token|1", text.Text)
+
+	err = ParseFromURLContext(context.Background(), server.URL, text, WithBasicAuth("user", "pass"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Go-http-client/1.1|Basic dXNlcjpwYXNz|", text.Text)
+}
+
+func TestParseFromURLContextStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<html><body><div id="text">Not found</div></body></html>`)
+	}))
+	defer server.Close()
+
+	text := &Text{}
+	err := ParseFromURLContext(context.Background(), server.URL, text)
+	statusErr := &StatusError{}
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusNotFound, statusErr.Response.StatusCode)
+	assert.Equal(t, server.URL, statusErr.URL)
+	assert.Equal(t, "", text.Text)
+}
+
+func TestParseFromURLContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := ParseFromURLContext(ctx, server.URL, &Text{})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	closed *int
+}
+
+func (b *trackedBody) Close() error {
+	*b.closed++
+	return b.ReadCloser.Close()
+}
+
+type bodyCloser struct {
+	transport http.RoundTripper
+	closed    int
+}
+
+func (b *bodyCloser) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := b.transport.RoundTrip(req)
+	if err == nil {
+		resp.Body = &trackedBody{ReadCloser: resp.Body, closed: &b.closed}
+	}
+	return resp, err
+}
+
+func TestParseFromURLContextClosesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><div id="text">x</div></body></html>`)
+	}))
+	defer server.Close()
+
+	closer := &bodyCloser{transport: http.DefaultTransport}
+	client := &http.Client{Transport: closer}
+	err := ParseFromURLContext(context.Background(), server.URL, &Int{}, WithHTTPClient(client))
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, closer.closed)
+}
@@ -0,0 +1,285 @@
+package gordom
+
+import (
+	"context"
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+const defaultFollowLimit = 4
+
+// fetcher fetches pages of follow fields. It's shared by all parsers of a fetching call,
+// so the number of concurrent requests is limited for the whole call and every page is fetched once.
+type fetcher struct {
+	ctx     context.Context
+	decoder *Decoder
+	slots   chan struct{}
+	mu      sync.Mutex
+	pages   map[string]*fetchedPage
+	visited map[string]bool
+}
+
+// fetchedPage is shared by all fields following the same link
+type fetchedPage struct {
+	once    sync.Once
+	html    []byte
+	pageURL *url.URL
+	err     error
+}
+
+func (d *Decoder) newFetcher(ctx context.Context) *fetcher {
+	limit := d.opts.followLimit
+	if limit < 1 {
+		limit = 1
+	}
+	return &fetcher{
+		ctx:     ctx,
+		decoder: d,
+		slots:   make(chan struct{}, limit),
+		pages:   map[string]*fetchedPage{},
+		visited: map[string]bool{},
+	}
+}
+
+// decodePage maps the fetched page to the struct and follows links of the page
+func (f *fetcher) decodePage(doc *node.Document, ptr interface{}) error {
+	if doc.Body == nil {
+		return ErrNilNode
+	}
+	p, err := f.decoder.documentParser(doc)
+	if err != nil {
+		return err
+	}
+	p.fetcher = f
+	f.visit(pageKey(doc.URL))
+	return p.parseByType(doc.Body, ptr)
+}
+
+// fetchDocument fetches the page once per call. Every link gets its own parsed document,
+// since documents cache text of nodes and can't be decoded concurrently.
+func (f *fetcher) fetchDocument(link string) (*node.Document, error) {
+	f.mu.Lock()
+	page, has := f.pages[link]
+	if !has {
+		page = &fetchedPage{}
+		f.pages[link] = page
+	}
+	f.mu.Unlock()
+
+	page.once.Do(func() {
+		f.slots <- struct{}{}
+		defer func() {
+			<-f.slots
+		}()
+		page.html, page.pageURL, page.err = f.decoder.fetchPage(f.ctx, link)
+	})
+	if page.err != nil {
+		return nil, page.err
+	}
+	return f.decoder.parsePage(page.html, page.pageURL), nil
+}
+
+// visit marks the page as visited and reports whether it hasn't been visited before
+func (f *fetcher) visit(link string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.visited[link] {
+		return false
+	}
+	f.visited[link] = true
+	return true
+}
+
+// compileFollow compiles the follow tag. The field should be a struct, a pointer to struct or a slice of them.
+func compileFollow(tag string) (*fieldPlan, error) {
+	selector, rest := splitSelector(tag)
+	if rest == "" {
+		rest = "[href]"
+	}
+	if selector != "" {
+		rest = selector + "|" + rest
+	}
+	return compileKey(rest)
+}
+
+// followedPlan returns the plan of the struct decoded from a followed page
+func followedPlan(typ *typePlan) *typePlan {
+	if typ.kind == sliceKind {
+		typ = typ.elem
+	}
+	if typ.kind == ptrKind {
+		typ = typ.elem
+	}
+	if typ.kind != structKind {
+		return nil
+	}
+	return typ
+}
+
+// parseFollow fetches pages linked from found nodes and decodes them to the field.
+// Links are followed only by fetching functions, the field stays empty otherwise.
+func (p *parser) parseFollow(n *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	if p.fetcher == nil {
+		return nil
+	}
+	if depth := p.opts.followDepth; depth > 0 && p.depth >= depth {
+		return nil
+	}
+	found, err := findLinks(n, field)
+	if err != nil {
+		return err
+	}
+
+	links := p.followLinks(found, field)
+	plan := followedPlan(field.typ)
+	values := make([]reflect.Value, len(links))
+	errs := make([]error, len(links))
+	wg := sync.WaitGroup{}
+	for i, link := range links {
+		wg.Add(1)
+		go func(i int, link string) {
+			defer wg.Done()
+			values[i], errs[i] = p.followLink(link, field, plan)
+		}(i, link)
+	}
+	wg.Wait()
+	return p.setFollowed(field, valueField, values, errs)
+}
+
+// findLinks returns nodes with links. If the field has a selector, a link is searched in every found node,
+// otherwise links are searched in the current node.
+func findLinks(n *node.Node, field *fieldPlan) ([]*node.Node, error) {
+	many := field.typ.kind == sliceKind
+	if len(field.queries) == 0 && field.label == "" {
+		var links []*node.Node
+		if many {
+			links = findMany(n, field.follow)
+		} else if link := findOne(n, field.follow); link != nil {
+			links = append(links, link)
+		}
+		if err := checkRequired(len(links) > 0, field); err != nil {
+			return nil, err
+		}
+		return links, checkCount(len(links), field)
+	}
+
+	var found []*node.Node
+	if many {
+		nodes, err := findManyChecked(n, field)
+		if err != nil {
+			return nil, err
+		}
+		found = nodes
+	} else {
+		one, err := findChecked(n, field)
+		if err != nil || one == nil {
+			return nil, err
+		}
+		found = append(found, one)
+	}
+	var links []*node.Node
+	for _, scope := range found {
+		if link := findOne(scope, field.follow); link != nil {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// followLinks returns distinct absolute links of the nodes. Fields of recursive types follow a page once
+// per call, so links to already visited pages are skipped.
+func (p *parser) followLinks(found []*node.Node, field *fieldPlan) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, linkNode := range found {
+		link, err := p.getValue(linkNode, field.follow)
+		if err != nil || link == "" {
+			continue
+		}
+		u, err := p.base.Parse(link)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+		u.Fragment = ""
+		link = u.String()
+		if seen[link] || field.recursive && !p.fetcher.visit(link) {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+func (p *parser) followLink(link string, field *fieldPlan, plan *typePlan) (reflect.Value, error) {
+	doc, err := p.fetcher.fetchDocument(link)
+	if err != nil {
+		return reflect.Value{}, &FieldError{Value: link, Err: err}
+	}
+	if doc.Body == nil {
+		return reflect.Value{}, &FieldError{Value: link, Err: ErrNilNode}
+	}
+	child, err := p.fetcher.decoder.documentParser(doc)
+	if err != nil {
+		return reflect.Value{}, &FieldError{Value: link, Err: err}
+	}
+	if pageURL := pageKey(doc.URL); field.recursive && pageURL != link && !p.fetcher.visit(pageURL) {
+		return reflect.Value{}, nil
+	}
+	child.fetcher = p.fetcher
+	child.depth = p.depth + 1
+	value := reflect.New(plan.t).Elem()
+	return child.setFields(doc.Body, value, plan)
+}
+
+func (p *parser) setFollowed(field *fieldPlan, valueField reflect.Value, values []reflect.Value, errs []error) error {
+	var fieldErrs FieldErrors
+	var elems []reflect.Value
+	for i, err := range errs {
+		if err != nil {
+			if field.typ.kind == sliceKind {
+				err = withIndex(err, i)
+			}
+			if !p.opts.lenient {
+				return err
+			}
+			fieldErrs = appendErrors(fieldErrs, err)
+		}
+		if values[i].IsValid() {
+			elems = append(elems, values[i])
+		}
+	}
+
+	switch field.typ.kind {
+	case sliceKind:
+		slice := reflect.MakeSlice(field.typ.t, 0, len(elems))
+		for _, elem := range elems {
+			slice = reflect.Append(slice, toFollowedType(elem, field.typ.elem))
+		}
+		valueField.Set(slice)
+	default:
+		if len(elems) > 0 {
+			valueField.Set(toFollowedType(elems[0], field.typ))
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return fieldErrs
+	}
+	return nil
+}
+
+func toFollowedType(value reflect.Value, typ *typePlan) reflect.Value {
+	if typ.kind != ptrKind {
+		return value
+	}
+	ptr := reflect.New(typ.elem.t)
+	ptr.Elem().Set(value)
+	return ptr
+}
+
+func invalidFollow(t reflect.Type) error {
+	return fmt.Errorf("%w: follow tag is used for %s", ErrInvalidTag, t)
+}
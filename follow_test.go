@@ -0,0 +1,264 @@
+package gordom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type FileDetail struct {
+	Name string `$:"h1"`
+	Size int    `$:".size"`
+}
+
+type FileItem struct {
+	Link   string      `$:"a" value:"[href]"`
+	Detail *FileDetail `follow:"a"`
+}
+
+type Project struct {
+	Name    string       `$:"h1"`
+	Files   []FileDetail `follow:"a.file"`
+	Items   []FileItem   `$:"li"`
+	First   FileDetail   `follow:"a.file"`
+	Readme  *FileDetail  `$:".readme" follow:"[data-url]"`
+	Missing *FileDetail  `follow:"a.missing"`
+}
+
+type projectServer struct {
+	*httptest.Server
+	requests    int32
+	inFlight    int32
+	maxInFlight int32
+}
+
+func newProjectServer(files int) *projectServer {
+	s := &projectServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		inFlight := atomic.AddInt32(&s.inFlight, 1)
+		defer atomic.AddInt32(&s.inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&s.maxInFlight)
+			if inFlight <= max || atomic.CompareAndSwapInt32(&s.maxInFlight, max, inFlight) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if r.URL.Path == "/project/" {
+			b := &strings.Builder{}
+			b.WriteString(`<html><head><base href="/files/"></head><body><h1>gordom</h1><ul>`)
+			for i := 1; i <= files; i++ {
+				fmt.Fprintf(b, `<li><a class="file" href="%d">file %d</a></li>`, i, i)
+			}
+			b.WriteString(`</ul><div class="readme" data-url="/readme#top"></div></body></html>`)
+			fmt.Fprint(w, b.String())
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/files/")
+		if r.URL.Path == "/readme" {
+			name = "README"
+		} else if name == "broken" || !strings.HasPrefix(r.URL.Path, "/files/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><h1>%s</h1><span class="size">%d</span></body></html>`, name, len(name))
+	}))
+	return s
+}
+
+func TestParseFollow(t *testing.T) {
+	server := newProjectServer(6)
+	defer server.Close()
+
+	project := &Project{}
+	err := ParseFromURLContext(context.Background(), server.URL+"/project/", project, WithFollowLimit(2))
+	assert.Nil(t, err)
+	assert.Equal(t, "gordom", project.Name)
+	assert.Equal(t, 6, len(project.Files))
+	for i, file := range project.Files {
+		assert.Equal(t, fmt.Sprint(i+1), file.Name)
+	}
+	assert.Equal(t, 6, len(project.Items))
+	assert.Equal(t, "6", project.Items[5].Detail.Name)
+	assert.Equal(t, FileDetail{Name: "1", Size: 1}, project.First)
+	assert.Equal(t, &FileDetail{Name: "README", Size: 6}, project.Readme)
+	assert.Nil(t, project.Missing)
+	assert.True(t, atomic.LoadInt32(&server.maxInFlight) <= 2)
+}
+
+func TestParseFollowWithoutFetching(t *testing.T) {
+	project := &Project{}
+	assert.Nil(t, Parse(`<body><h1>gordom</h1><a class="file" href="http://localhost/1">1</a></body>`, project))
+	assert.Equal(t, "gordom", project.Name)
+	assert.Nil(t, project.Files)
+}
+
+type BrokenProject struct {
+	Files []FileDetail `follow:"a"`
+}
+
+func TestParseFollowError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/ok">ok</a><a href="/broken">broken</a></body></html>`)
+		case "/ok":
+			fmt.Fprint(w, `<html><body><h1>ok</h1><span class="size">2</span></body></html>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	project := &BrokenProject{}
+	err := ParseFromURLContext(context.Background(), server.URL, project)
+	statusErr := &StatusError{}
+	assert.True(t, errors.As(err, &statusErr))
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Files[1]", fieldErr.Path)
+	assert.Equal(t, server.URL+"/broken", fieldErr.Value)
+
+	err = ParseFromURLContext(context.Background(), server.URL, project, WithLenient())
+	assert.NotNil(t, err)
+	assert.Equal(t, []FileDetail{{Name: "ok", Size: 2}}, project.Files)
+}
+
+type CyclicPage struct {
+	Title string      `$:"h1"`
+	Next  *CyclicPage `follow:"a"`
+}
+
+func TestParseFollowCycle(t *testing.T) {
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		next := map[string]string{"/a": "/b", "/b": "/a#top"}[r.URL.Path]
+		fmt.Fprintf(w, `<html><body><h1>%s</h1><a href="%s">next</a></body></html>`, r.URL.Path, next)
+	}))
+	defer server.Close()
+
+	page := &CyclicPage{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/a", page))
+	assert.Equal(t, "/a", page.Title)
+	assert.Equal(t, "/b", page.Next.Title)
+	assert.Nil(t, page.Next.Next)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestParseFollowDuplicates(t *testing.T) {
+	server := newProjectServer(2)
+	defer server.Close()
+
+	project := &Project{}
+	err := ParseFromURLContext(context.Background(), server.URL+"/project/", project)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(project.Files))
+	assert.Equal(t, "1", project.Items[0].Detail.Name)
+	assert.Equal(t, "1", project.First.Name)
+	// the project, 2 files and the readme, files are shared by Files, Items and First
+	assert.Equal(t, int32(4), atomic.LoadInt32(&server.requests))
+}
+
+type SharedLeaf struct {
+	Name string `$:"h1"`
+}
+
+type SharedMid struct {
+	Shared SharedLeaf `follow:"a.leaf"`
+}
+
+type SharedRoot struct {
+	Pages []SharedMid `follow:"a.mid"`
+}
+
+func TestParseFollowShared(t *testing.T) {
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><a class="mid" href="/a">a</a><a class="mid" href="/b">b</a></body></html>`)
+		case "/leaf":
+			fmt.Fprint(w, `<html><body><h1>leaf <b>page</b></h1></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body><a class="leaf" href="/leaf">leaf</a></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	root := &SharedRoot{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL, root))
+	assert.Equal(t, 2, len(root.Pages))
+	for _, page := range root.Pages {
+		assert.Equal(t, "leaf page", page.Shared.Name)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+}
+
+type RelatedProduct struct {
+	Name    string           `$:"h1"`
+	Related []RelatedProduct `follow:"a.related"`
+}
+
+// productServer serves products linked to all other products
+func productServer(n int) (*httptest.Server, *int32) {
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		b := &strings.Builder{}
+		fmt.Fprintf(b, `<html><body><h1>%s</h1>`, r.URL.Path)
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(b, `<a class="related" href="/%d">%d</a><a class="related" href="/%d">%d</a>`, i, i, i, i)
+		}
+		b.WriteString(`</body></html>`)
+		fmt.Fprint(w, b.String())
+	}))
+	return server, &requests
+}
+
+func TestParseFollowVisited(t *testing.T) {
+	server, requests := productServer(6)
+	defer server.Close()
+
+	product := &RelatedProduct{}
+	err := ParseFromURLContext(context.Background(), server.URL+"/1", product)
+	assert.Nil(t, err)
+	assert.Equal(t, "/1", product.Name)
+	assert.Equal(t, 5, len(product.Related))
+	for _, related := range product.Related {
+		assert.Empty(t, related.Related)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(requests))
+}
+
+func TestParseFollowDepth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := map[string]string{"/a": "/b", "/b": "/c"}[r.URL.Path]
+		fmt.Fprintf(w, `<html><body><h1>%s</h1><a href="%s">next</a></body></html>`, r.URL.Path, next)
+	}))
+	defer server.Close()
+
+	page := &CyclicPage{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/a", page, WithFollowDepth(1)))
+	assert.Equal(t, "/b", page.Next.Title)
+	assert.Nil(t, page.Next.Next)
+}
+
+type InvalidFollow struct {
+	Names []string `follow:"a"`
+}
+
+func TestCompileFollow(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&InvalidFollow{}), ErrInvalidTag))
+	assert.Nil(t, Compile(&Project{}))
+}
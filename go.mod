@@ -5,5 +5,6 @@ go 1.14
 require (
 	github.com/stretchr/testify v1.5.1
 	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e
+	golang.org/x/text v0.3.0
 	gopkg.in/yaml.v2 v2.2.4 // indirect
 )
@@ -0,0 +1,75 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"strings"
+)
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimRight(NormalizeSpace(label), ": "))
+}
+
+func compileLabel(label string) (string, error) {
+	normalized := normalizeLabel(label)
+	if normalized == "" {
+		return "", fmt.Errorf("%w: label:%q", ErrInvalidTag, label)
+	}
+	return normalized, nil
+}
+
+func labelChecker(label string) node.Checker {
+	return func(n *node.Node) bool {
+		return n.Tag != "" && normalizeLabel(n.OwnText()) == label
+	}
+}
+
+// findLabeled returns value nodes of labels found in the node, or in the nodes found by the field selector
+func findLabeled(n *node.Node, field *fieldPlan) []*node.Node {
+	scopes := []*node.Node{n}
+	if len(field.queries) > 0 {
+		scopes = findMany(n, &fieldPlan{queries: field.queries})
+	}
+	checker := labelChecker(field.label)
+	var found []*node.Node
+	for _, scope := range scopes {
+		for _, child := range scope.Children {
+			for _, label := range child.Filter(checker) {
+				if value := labelValue(label, scope); value != nil {
+					found = append(found, value)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// labelValue returns the node with a value of the label. If the label has no next brother, e.g. <dt><b>Label</b></dt>,
+// the brother of its parent is used.
+func labelValue(label *node.Node, scope *node.Node) *node.Node {
+	for n := label; n != nil && n != scope; n = n.Parent {
+		if value := nextValueBrother(n); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// nextValueBrother returns the next dd for dt, the next cell for a table cell or just the next brother otherwise
+func nextValueBrother(n *node.Node) *node.Node {
+	for _, brother := range n.NextBrothers() {
+		switch n.Tag {
+		case "dt":
+			if brother.Tag == "dd" {
+				return brother
+			}
+		case "th", "td":
+			if brother.Tag == "td" || brother.Tag == "th" {
+				return brother
+			}
+		default:
+			return brother
+		}
+	}
+	return nil
+}
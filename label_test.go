@@ -0,0 +1,67 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Labeled struct {
+	Price    float64  `label:"Price"`
+	Sku      string   `label:"sku"`
+	Brand    string   `label:"Brand"`
+	Weight   string   `label:"Weight"`
+	Color    string   `$:".details" label:"Color"`
+	Site     string   `label:"Site" value:"[href]"`
+	Authors  []string `label:"Author"`
+	Missing  *string  `label:"Missing"`
+	Required string   `label:"Stock" default:"none"`
+}
+
+func TestParseLabel(t *testing.T) {
+	html := `
+	<body>
+		<div class="summary">
+			<span>Price:</span><b>12.5</b>
+			<p><span>SKU</span> A-1</p>
+			<span>Color</span><span>red</span>
+			<span>Site:</span> <a href="https://acme.com">acme.com</a>
+		</div>
+		<dl>
+			<dt><b>Brand</b></dt>
+			<dd>Acme</dd>
+		</dl>
+		<table>
+			<tr><th>Weight</th><td>1 kg</td></tr>
+			<tr><td>Author</td><td>Ann</td></tr>
+			<tr><td>Author</td><td>Bob</td></tr>
+		</table>
+		<div class="details"><span>Color</span><span>blue</span></div>
+	</body>
+	`
+	labeled := &Labeled{}
+	err := Parse(html, labeled)
+	assert.Nil(t, err)
+	assert.Equal(t, 12.5, labeled.Price)
+	assert.Equal(t, "A-1", labeled.Sku)
+	assert.Equal(t, "Acme", labeled.Brand)
+	assert.Equal(t, "1 kg", labeled.Weight)
+	assert.Equal(t, "blue", labeled.Color)
+	assert.Equal(t, "https://acme.com", labeled.Site)
+	assert.Equal(t, []string{"Ann", "Bob"}, labeled.Authors)
+	assert.Nil(t, labeled.Missing)
+	assert.Equal(t, "none", labeled.Required)
+}
+
+type LabelRequired struct {
+	Price string `label:"Price" required:"true"`
+}
+
+type LabelInvalid struct {
+	Price string `label:" : "`
+}
+
+func TestParseLabelError(t *testing.T) {
+	assert.True(t, errors.Is(Parse(`<body><span>Price</span></body>`, &LabelRequired{}), ErrRequired))
+	assert.True(t, errors.Is(Compile(&LabelInvalid{}), ErrInvalidTag))
+}
@@ -0,0 +1,115 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"reflect"
+	"strings"
+)
+
+// splitSelector splits a key or value tag of a map field like "th|trim" to a selector of a descendant node and
+// the rest of the tag. There is no selector if the tag starts with a value source or a transform.
+func splitSelector(tag string) (string, string) {
+	selector, rest := tag, ""
+	if i := strings.Index(tag, "|"); i >= 0 {
+		selector, rest = tag[:i], tag[i+1:]
+	}
+	if selector == "" || isValueSource(selector) {
+		return "", tag
+	}
+	if _, _, err := lookupTransform(selector); err == nil {
+		return "", tag
+	}
+	return selector, rest
+}
+
+// compileKey compiles the key tag to a plan of a string field, so the key is found and extracted like a field value
+func compileKey(tag string) (*fieldPlan, error) {
+	selector, rest := splitSelector(tag)
+	key := &fieldPlan{selector: selector}
+	var err error
+	if selector != "" {
+		if key.queries, err = compileSelector("key", selector); err != nil {
+			return nil, err
+		}
+	}
+	sources, steps := splitValueTag(rest)
+	key.sources = sources
+	if key.transforms, err = compileTransforms(steps); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func isMapKey(t reflect.Type) bool {
+	return t.Kind() == reflect.String || isNumberKind(t.Kind())
+}
+
+func (c *planCompiler) compileMap(t reflect.Type) (*typePlan, error) {
+	if t.Kind() != reflect.Map || !isMapKey(t.Key()) {
+		return nil, fmt.Errorf("%w: key tag is used for %s", ErrInvalidTag, t)
+	}
+	elem, err := c.compileType(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &typePlan{kind: mapKind, t: t, elem: elem}, nil
+}
+
+func (p *parser) parseMap(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findManyChecked(node, field)
+	if err != nil {
+		return err
+	}
+	entries := reflect.MakeMap(field.typ.t)
+	var errs FieldErrors
+	for i, n := range found {
+		key, value, err := p.createNewEntry(n, i, field)
+		if err != nil {
+			if !p.opts.lenient {
+				return err
+			}
+			errs = appendErrors(errs, err)
+		}
+		if key.IsValid() && value.IsValid() {
+			entries.SetMapIndex(key, value)
+		}
+	}
+	valueField.Set(entries)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// createNewEntry returns invalid values if the key or the value node isn't found, so the entry is skipped.
+// Errors of the key have the index of the node in the path, errors of the value have the key.
+func (p *parser) createNewEntry(n *node.Node, i int, field *fieldPlan) (reflect.Value, reflect.Value, error) {
+	keyNode := findOne(n, field.key)
+	if keyNode == nil {
+		return reflect.Value{}, reflect.Value{}, nil
+	}
+	text, err := p.getValue(keyNode, field.key)
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, withIndex(err, i)
+	}
+	key := reflect.New(field.typ.t.Key()).Elem()
+	if err := p.parseScalar(text, field, key); err != nil {
+		return reflect.Value{}, reflect.Value{}, withIndex(err, i)
+	}
+
+	valueNode := n
+	if len(field.valueQueries) > 0 {
+		if valueNode = findFirst(n, field.valueQueries); valueNode == nil {
+			valueNode = defaultNode(field)
+		}
+		if valueNode == nil {
+			return reflect.Value{}, reflect.Value{}, nil
+		}
+	}
+	value, err := p.createNewValue(valueNode, field, field.typ.elem)
+	if err != nil {
+		return key, value, withKey(err, text)
+	}
+	return key, value, nil
+}
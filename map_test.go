@@ -0,0 +1,120 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Spec struct {
+	Value string `$:"td"`
+	Unit  string `$:"td" value:"[data-unit]"`
+}
+
+type Specs struct {
+	Specs   map[string]string `$:"table.specs tr" key:"th" value:"td"`
+	Lower   map[string]string `$:"table.specs tr" key:"th|lower" value:"td|trim"`
+	Structs map[string]Spec   `$:"table.specs tr" key:"th"`
+	Numbers map[string]int    `$:"dl.stats div" key:"dt" value:"dd|replace:,:"`
+	Ids     map[int]string    `$:"ul li" key:"[data-id]" value:"owntext"`
+	Links   map[string]*Image `$:"ul li" key:"owntext" value:"img"`
+}
+
+func TestParseMap(t *testing.T) {
+	html := `
+	<body>
+		<table class="specs">
+			<tr><th colspan="2">Header</th></tr>
+			<tr><th>Weight</th><td data-unit="kg">1.5</td></tr>
+			<tr><th>Color</th><td> red </td></tr>
+		</table>
+		<dl class="stats">
+			<div><dt>Stars</dt><dd>1,234</dd></div>
+			<div><dt>Forks</dt><dd>56</dd></div>
+		</dl>
+		<ul>
+			<li data-id="1">One<img src="1.png"/></li>
+			<li data-id="2">Two</li>
+		</ul>
+	</body>
+	`
+	specs := &Specs{}
+	err := Parse(html, specs)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Weight": "1.5", "Color": "red"}, specs.Specs)
+	assert.Equal(t, map[string]string{"weight": "1.5", "color": "red"}, specs.Lower)
+	assert.Equal(t, Spec{Value: "1.5", Unit: "kg"}, specs.Structs["Weight"])
+	assert.Equal(t, Spec{}, specs.Structs["Header"])
+	assert.Equal(t, map[string]int{"Stars": 1234, "Forks": 56}, specs.Numbers)
+	assert.Equal(t, map[int]string{1: "One", 2: "Two"}, specs.Ids)
+	assert.Equal(t, 1, len(specs.Links))
+	assert.Equal(t, "1.png", specs.Links["One"].Src)
+}
+
+type MapError struct {
+	Numbers map[string]int `$:"tr" key:"th" value:"td" required:"true"`
+}
+
+type MapNotFound struct {
+	Numbers map[string]int `$:"tr" key:"th" value:"td"`
+}
+
+func TestParseMapError(t *testing.T) {
+	html := `
+	<body>
+		<table>
+			<tr><th>Stars</th><td>12</td></tr>
+			<tr><th>Forks</th><td>many</td></tr>
+		</table>
+	</body>
+	`
+	numbers := &MapError{}
+	err := Parse(html, numbers)
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, `Numbers["Forks"]`, fieldErr.Path)
+	assert.Equal(t, "many", fieldErr.Value)
+
+	err = Parse(html, numbers, WithLenient())
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]int{"Stars": 12, "Forks": 0}, numbers.Numbers)
+
+	err = Parse(`<body></body>`, numbers)
+	assert.True(t, errors.Is(err, ErrRequired))
+
+	notFound := &MapNotFound{}
+	assert.Nil(t, Parse(`<body></body>`, notFound))
+	assert.Equal(t, map[string]int{}, notFound.Numbers)
+}
+
+type MapInvalidType struct {
+	Items []string `$:"li" key:"b"`
+}
+
+type MapInvalidKey struct {
+	Items map[bool]string `$:"li" key:"b"`
+}
+
+func TestCompileMapError(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&MapInvalidType{}), ErrInvalidTag))
+	assert.True(t, errors.Is(Compile(&MapInvalidKey{}), ErrInvalidTag))
+}
+
+func TestSplitSelector(t *testing.T) {
+	cases := []struct {
+		tag, selector, rest string
+	}{
+		{"th", "th", ""},
+		{"th|lower", "th", "lower"},
+		{"[data-id]", "", "[data-id]"},
+		{"text|trim", "", "text|trim"},
+		{"trim", "", "trim"},
+		{"", "", ""},
+		{"td.value|[title]|trim", "td.value", "[title]|trim"},
+	}
+	for _, c := range cases {
+		selector, rest := splitSelector(c.tag)
+		assert.Equal(t, c.selector, selector, c.tag)
+		assert.Equal(t, c.rest, rest, c.tag)
+	}
+}
@@ -1,8 +1,17 @@
 package node
 
+import (
+	"net/url"
+	"strings"
+)
+
+// urlAttrs are attributes with a single url, srcset is handled separately
+var urlAttrs = []string{"action", "background", "cite", "data", "formaction", "href", "poster", "src"}
+
 type Document struct {
 	Body *Node
 	Head *Node
+	URL  *url.URL
 }
 
 func NewDocument(body, head *Node) *Document {
@@ -19,3 +28,94 @@ func (doc *Document) Select(query string) []*Node {
 func (doc *Document) SelectOne(query string) *Node {
 	return doc.Body.SelectOne(query)
 }
+
+// BaseURL returns the url of <base href> resolved against the document url, or the document url itself.
+// It's nil if the document url is unknown and there is no absolute <base href>.
+func (doc *Document) BaseURL() *url.URL {
+	if doc.Head == nil {
+		return doc.URL
+	}
+	base := doc.Head.SelectOne("base[href]")
+	if base == nil {
+		return doc.URL
+	}
+	href, err := url.Parse(strings.TrimSpace(base.Attrs["href"]))
+	if err != nil {
+		return doc.URL
+	}
+	if doc.URL != nil {
+		return doc.URL.ResolveReference(href)
+	}
+	if href.IsAbs() {
+		return href
+	}
+	return nil
+}
+
+// ResolveURL resolves the reference against the base url of the document.
+// The reference is returned as is if it can't be resolved.
+func (doc *Document) ResolveURL(ref string) string {
+	return ResolveURL(doc.BaseURL(), ref)
+}
+
+// AbsoluteURL returns the url in the attribute of the node resolved against the base url of the document.
+// It's empty if there is no such attribute.
+func (doc *Document) AbsoluteURL(n *Node, attr string) string {
+	value, has := n.Attrs[attr]
+	if !has {
+		return ""
+	}
+	return doc.ResolveURL(value)
+}
+
+// ResolveURLs rewrites link attributes like href, src and srcset of all nodes to absolute urls
+func (doc *Document) ResolveURLs() {
+	base := doc.BaseURL()
+	doc.Head.ResolveURLs(base)
+	doc.Body.ResolveURLs(base)
+}
+
+// ResolveURLs rewrites link attributes of the node and its descendants to urls resolved against the base.
+// Nothing is changed if the node or the base is nil.
+func (node *Node) ResolveURLs(base *url.URL) {
+	if node == nil || base == nil {
+		return
+	}
+	node.ForEach(func(n *Node) {
+		for _, attr := range urlAttrs {
+			if value, has := n.Attrs[attr]; has {
+				n.Attrs[attr] = ResolveURL(base, value)
+			}
+		}
+		if srcset, has := n.Attrs["srcset"]; has {
+			n.Attrs["srcset"] = resolveSrcset(base, srcset)
+		}
+	})
+}
+
+// ResolveURL resolves the reference against the base url. The reference is returned as is
+// if the base is nil or the reference can't be parsed.
+func ResolveURL(base *url.URL, ref string) string {
+	if base == nil {
+		return ref
+	}
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// resolveSrcset resolves urls of a srcset like "img.png 1x, img@2x.png 2x"
+func resolveSrcset(base *url.URL, srcset string) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = ResolveURL(base, fields[0])
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"github.com/stretchr/testify/assert"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -409,3 +410,58 @@ func TestDocument_SelectContains(t *testing.T) {
 	got := doc.SelectOne("div:contains(Some)")
 	assert.Equal(t, "text", got.Id)
 }
+
+const urlsHtml = `
+<html>
+<head>
+	<base href="/docs/">
+	<link rel="stylesheet" href="style.css">
+</head>
+<body>
+	<a id="rel" href="../img.png">img</a>
+	<a id="abs" href="https://other.com/x">other</a>
+	<a id="frag" href="#top">top</a>
+	<a id="js" href="javascript:void(0)">js</a>
+	<img id="img" src="a.png" srcset="a.png 1x, a@2x.png 2x">
+	<form id="form" action="send"></form>
+</body>
+`
+
+func TestDocument_BaseURL(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(urlsHtml))
+	assert.Nil(t, doc.BaseURL())
+
+	doc.URL, _ = url.Parse("https://example.com/a/page.html")
+	assert.Equal(t, "https://example.com/docs/", doc.BaseURL().String())
+
+	doc = ParseHtml(strings.NewReader(`<html><head></head><body></body></html>`))
+	doc.URL, _ = url.Parse("https://example.com/a/page.html")
+	assert.Equal(t, "https://example.com/a/page.html", doc.BaseURL().String())
+
+	doc = ParseHtml(strings.NewReader(`<html><head><base href="https://cdn.com/"></head><body></body></html>`))
+	assert.Equal(t, "https://cdn.com/", doc.BaseURL().String())
+}
+
+func TestDocument_AbsoluteURL(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(urlsHtml))
+	assert.Equal(t, "../img.png", doc.AbsoluteURL(doc.SelectOne("#rel"), "href"))
+
+	doc.URL, _ = url.Parse("https://example.com/a/page.html")
+	assert.Equal(t, "https://example.com/img.png", doc.AbsoluteURL(doc.SelectOne("#rel"), "href"))
+	assert.Equal(t, "https://other.com/x", doc.AbsoluteURL(doc.SelectOne("#abs"), "href"))
+	assert.Equal(t, "https://example.com/docs/#top", doc.AbsoluteURL(doc.SelectOne("#frag"), "href"))
+	assert.Equal(t, "javascript:void(0)", doc.AbsoluteURL(doc.SelectOne("#js"), "href"))
+	assert.Equal(t, "", doc.AbsoluteURL(doc.SelectOne("#rel"), "src"))
+}
+
+func TestDocument_ResolveURLs(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(urlsHtml))
+	doc.URL, _ = url.Parse("https://example.com/a/page.html")
+	doc.ResolveURLs()
+	assert.Equal(t, "https://example.com/docs/style.css", doc.Head.SelectOne("link").Attrs["href"])
+	assert.Equal(t, "https://example.com/img.png", doc.SelectOne("#rel").Attrs["href"])
+	assert.Equal(t, "https://example.com/docs/a.png", doc.SelectOne("#img").Attrs["src"])
+	assert.Equal(t, "https://example.com/docs/a.png 1x, https://example.com/docs/a@2x.png 2x", doc.SelectOne("#img").Attrs["srcset"])
+	assert.Equal(t, "https://example.com/docs/send", doc.SelectOne("#form").Attrs["action"])
+	assert.Equal(t, "https://example.com/docs/", doc.Head.SelectOne("base").Attrs["href"])
+}
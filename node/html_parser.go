@@ -81,7 +81,7 @@ func startTagHandler(tokenizer *html.Tokenizer, node *Node) *Node {
 	tag, _ := tokenizer.TagName()
 	tagName := string(tag)
 	if isTagSelfClosing(tagName) {
-		selfClosingTagHandler(tokenizer, node)
+		createChild(tagName, tokenizer, node)
 		return node
 	}
 	return createChild(tagName, tokenizer, node)
@@ -24,3 +24,18 @@ func TestParse(t *testing.T) {
 	assert.Equal(t, "main", got.Body.FirstChild().Id)
 	assert.Equal(t, "img", got.Body.FirstChild().FirstChild().Tag)
 }
+
+func TestParseVoidTag(t *testing.T) {
+	s := `
+	<html>
+	<body>
+		<div id="main"><img src="img.png"><br></div>
+	</body>
+	`
+	got := ParseHtml(strings.NewReader(s))
+	main := got.Body.FirstChild()
+	assert.Equal(t, 2, len(main.Children))
+	assert.Equal(t, "img", main.FirstChild().Tag)
+	assert.Equal(t, "img.png", main.FirstChild().Attrs["src"])
+	assert.Equal(t, "br", main.LastChild().Tag)
+}
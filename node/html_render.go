@@ -0,0 +1,76 @@
+package node
+
+import (
+	"golang.org/x/net/html"
+	"sort"
+	"strings"
+)
+
+var rawTextTags = []string{"script", "style"}
+
+func isRawTextTag(tag string) bool {
+	for _, v := range rawTextTags {
+		if v == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (node *Node) InnerHTML() string {
+	b := &strings.Builder{}
+	node.writeChildren(b)
+	return b.String()
+}
+
+func (node *Node) OuterHTML() string {
+	b := &strings.Builder{}
+	node.write(b)
+	return b.String()
+}
+
+func (node *Node) OwnText() string {
+	texts := []string{}
+	if len(node.Text) != 0 {
+		texts = append(texts, node.Text)
+	}
+	node.ForEachChild(func(child *Node) {
+		if child.Tag == "" && len(child.Text) != 0 {
+			texts = append(texts, child.Text)
+		}
+	})
+	return strings.Join(texts, " ")
+}
+
+func (node *Node) write(b *strings.Builder) {
+	if node.Tag == "" {
+		if node.Parent != nil && isRawTextTag(node.Parent.Tag) {
+			b.WriteString(node.Text)
+		} else {
+			b.WriteString(html.EscapeString(node.Text))
+		}
+		return
+	}
+	b.WriteString("<" + node.Tag)
+	keys := make([]string, 0, len(node.Attrs))
+	for key := range node.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString(" " + key + `="` + html.EscapeString(node.Attrs[key]) + `"`)
+	}
+	if isTagSelfClosing(node.Tag) {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	node.writeChildren(b)
+	b.WriteString("</" + node.Tag + ">")
+}
+
+func (node *Node) writeChildren(b *strings.Builder) {
+	node.ForEachChild(func(child *Node) {
+		child.write(b)
+	})
+}
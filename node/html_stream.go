@@ -0,0 +1,84 @@
+package node
+
+import (
+	"golang.org/x/net/html"
+	"io"
+)
+
+// ParseHtmlEach parses html and calls fn for every element matching the checker as soon as the element is closed.
+// The element is removed from the tree after fn returns, so the memory doesn't grow with the number of elements.
+// Elements inside a matched element are passed as a part of it. Parsing stops on the first error returned by fn.
+func ParseHtmlEach(reader io.Reader, checker Checker, fn func(n *Node) error) error {
+	tokenizer := html.NewTokenizer(reader)
+	root := NewNode("", nil)
+	currentNode := root
+	emit := func(n *Node) error {
+		if n == root || n.Tag == "" || !checker(n) || hasMatchedParent(n, checker) {
+			return nil
+		}
+		err := fn(n)
+		n.detach()
+		return err
+	}
+	for {
+		var closed *Node
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return nil
+			}
+			return tokenizer.Err()
+		case html.StartTagToken:
+			next := startTagHandler(tokenizer, currentNode)
+			if next == currentNode {
+				closed = currentNode.LastChild()
+			}
+			currentNode = next
+		case html.EndTagToken:
+			next := endTagHandler(currentNode)
+			if next != currentNode {
+				closed = currentNode
+			}
+			currentNode = next
+		case html.SelfClosingTagToken:
+			currentNode = selfClosingTagHandler(tokenizer, currentNode)
+			closed = currentNode.LastChild()
+		case html.TextToken:
+			currentNode = textTokenHandler(tokenizer, currentNode)
+		}
+		if closed != nil {
+			if err := emit(closed); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// hasMatchedParent reports whether an ancestor of the node matches the checker. The root isn't checked,
+// it has no parent for checks like :first-child.
+func hasMatchedParent(n *Node, checker Checker) bool {
+	for parent := n.Parent; parent != nil && parent.Parent != nil; parent = parent.Parent {
+		if checker(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// detach removes the node from children of its parent and resets cached text of ancestors
+func (node *Node) detach() {
+	parent := node.Parent
+	if parent == nil {
+		return
+	}
+	for i, child := range parent.Children {
+		if child == node {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+	for ; parent != nil; parent = parent.Parent {
+		parent.innerText = ""
+	}
+	node.Parent = nil
+}
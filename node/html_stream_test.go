@@ -0,0 +1,60 @@
+package node
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const streamHtml = `
+<html>
+<body>
+	<ul id="list">
+		<li class="item"><b>1</b></li>
+		<li class="item"><b>2</b><ul><li class="item">nested</li></ul></li>
+		<li class="item"><img src="3.png"/></li>
+	</ul>
+	<img class="item" src="4.png">
+</body>
+</html>
+`
+
+func TestParseHtmlEach(t *testing.T) {
+	var texts []string
+	var lists []*Node
+	err := ParseHtmlEach(strings.NewReader(streamHtml), CompileQuery(".item"), func(n *Node) error {
+		texts = append(texts, n.InnerText())
+		if n.Parent != nil && n.Parent.Id == "list" {
+			lists = append(lists, n.Parent)
+			assert.Equal(t, 1, len(n.Parent.Children))
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1", "2 nested", "", ""}, texts)
+	assert.Equal(t, 3, len(lists))
+	assert.Empty(t, lists[0].Children)
+}
+
+func TestParseHtmlEachError(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := ParseHtmlEach(strings.NewReader(streamHtml), CompileQuery(".item"), func(n *Node) error {
+		count++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestParseHtmlEachPseudoClass(t *testing.T) {
+	var texts []string
+	err := ParseHtmlEach(strings.NewReader(streamHtml), CompileQuery("ul > li:first-child"), func(n *Node) error {
+		texts = append(texts, n.InnerText())
+		return nil
+	})
+	assert.Nil(t, err)
+	// earlier matches are detached, so every item is the first child when it's closed
+	assert.Equal(t, []string{"1", "2 nested", ""}, texts)
+}
@@ -82,6 +82,26 @@ func (node *Node) LastChild() *Node {
 	return node.Children[index]
 }
 
+func (node *Node) NextBrother() *Node {
+	brothers := node.Brothers()
+	for i, brother := range brothers {
+		if brother == node && i != len(brothers)-1 {
+			return brothers[i+1]
+		}
+	}
+	return nil
+}
+
+func (node *Node) NextBrothers() []*Node {
+	brothers := node.Brothers()
+	for i, brother := range brothers {
+		if brother == node {
+			return brothers[i+1:]
+		}
+	}
+	return []*Node{}
+}
+
 func (node *Node) Parents() []*Node {
 	if node.Parent == nil {
 		return []*Node{}
@@ -2,6 +2,8 @@ package node
 
 import (
 	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -119,6 +121,25 @@ func TestNode_PrevBrothersEmpty2(t *testing.T) {
 	assert.Empty(t, parentNode.PrevBrothers())
 }
 
+func TestNode_NextBrother(t *testing.T) {
+	parentNode := NewNode("a", nil)
+	n1 := parentNode.CreateChild("b")
+	n2 := parentNode.CreateChild("c")
+	assert.Equal(t, n2, n1.NextBrother())
+	assert.Nil(t, n2.NextBrother())
+	assert.Nil(t, parentNode.NextBrother())
+}
+
+func TestNode_NextBrothers(t *testing.T) {
+	parentNode := NewNode("a", nil)
+	n1 := parentNode.CreateChild("b")
+	n2 := parentNode.CreateChild("c")
+	n3 := parentNode.CreateChild("d")
+	assert.Equal(t, []*Node{n2, n3}, n1.NextBrothers())
+	assert.Empty(t, n3.NextBrothers())
+	assert.Empty(t, parentNode.NextBrothers())
+}
+
 func TestNode_String(t *testing.T) {
 	parentNode := NewNode("a", nil)
 	node := NewNode("b", parentNode)
@@ -127,3 +148,35 @@ func TestNode_String(t *testing.T) {
 	node.Text = "test"
 	assert.Equal(t, node.String(), "b.a.b[a=a]")
 }
+
+func TestNode_InnerHTML(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(`<html><body><div id="a" class="b">x &amp; <b>y</b><br><img src="i.png"></div></body></html>`))
+	div := doc.SelectOne("#a")
+	assert.Equal(t, `x &amp;<b>y</b><br/><img src="i.png"/>`, div.InnerHTML())
+	assert.Equal(t, `<div class="b" id="a">x &amp;<b>y</b><br/><img src="i.png"/></div>`, div.OuterHTML())
+}
+
+func TestNode_InnerHTMLRawText(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(`<html><body><script>if (a < b) {}</script></body></html>`))
+	assert.Equal(t, `if (a < b) {}`, doc.SelectOne("script").InnerHTML())
+}
+
+func TestNode_OwnText(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(`<html><body><div id="a">own <b>child</b> text</div></body></html>`))
+	div := doc.SelectOne("#a")
+	assert.Equal(t, "own text", div.OwnText())
+	assert.Equal(t, "own child text", div.InnerText())
+}
+
+func TestNode_ResolveURLs(t *testing.T) {
+	doc := ParseHtml(strings.NewReader(`<html><body><a href="x">x</a><div id="a"><a href="../y">y</a></div></body></html>`))
+	base, _ := url.Parse("https://example.com/a/b/")
+	doc.SelectOne("#a").ResolveURLs(base)
+	assert.Equal(t, "https://example.com/a/y", doc.SelectOne("#a a").Attrs["href"])
+	assert.Equal(t, "x", doc.SelectOne("a").Attrs["href"])
+
+	var nilNode *Node
+	nilNode.ResolveURLs(base)
+	assert.Equal(t, "x", ResolveURL(nil, "x"))
+	assert.Equal(t, "https://example.com/a/b/x", ResolveURL(base, " x "))
+}
@@ -0,0 +1,216 @@
+package gordom
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NumberFormat defines how numeric fields are parsed. It's set per field by the number tag,
+// e.g. number:"de", or per call by WithNumberFormat.
+//
+// Every format except NumberStrict ignores currency symbols and spaces, and accepts
+// SI suffixes (1.2k, 3.4M) and byte units (1.5 GB, 2 KiB).
+type NumberFormat string
+
+const (
+	// NumberStrict parses numbers by strconv
+	NumberStrict NumberFormat = "strict"
+	// NumberAuto guesses the decimal separator. A single separator followed by 3 digits is a group one.
+	NumberAuto NumberFormat = "auto"
+	// NumberEN parses numbers like 1,234.56
+	NumberEN NumberFormat = "en"
+	// NumberDE parses numbers like 1.234,56
+	NumberDE NumberFormat = "de"
+	// NumberFR parses numbers like 1 234,56
+	NumberFR NumberFormat = "fr"
+	// NumberCH parses numbers like 1'234.56
+	NumberCH NumberFormat = "ch"
+)
+
+type numberSeparators struct {
+	group   rune
+	decimal rune
+}
+
+var numberLocales = map[NumberFormat]numberSeparators{
+	NumberEN: {group: ',', decimal: '.'},
+	NumberDE: {group: '.', decimal: ','},
+	NumberFR: {group: ' ', decimal: ','},
+	NumberCH: {group: '\'', decimal: '.'},
+}
+
+var numberUnits = map[string]float64{
+	"":    1,
+	"k":   1e3,
+	"K":   1e3,
+	"M":   1e6,
+	"G":   1e9,
+	"T":   1e12,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+func isNumberFormat(format NumberFormat) bool {
+	_, has := numberLocales[format]
+	return has || format == NumberStrict || format == NumberAuto
+}
+
+func (p *parser) numberFormat(field *fieldPlan) (NumberFormat, error) {
+	if field.numberFormat != "" {
+		return field.numberFormat, nil
+	}
+	if !isNumberFormat(p.opts.numberFormat) {
+		return "", fmt.Errorf("unknown number format %q", p.opts.numberFormat)
+	}
+	return p.opts.numberFormat, nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+func isNumberSpace(ch rune) bool {
+	return unicode.IsSpace(ch) || ch == '\u00a0' || ch == '\u202f' || ch == '\u2009'
+}
+
+func isNumberRune(ch rune) bool {
+	return ch >= '0' && ch <= '9' || ch == '.' || ch == ',' || ch == '\'' || isNumberSpace(ch)
+}
+
+func numberSyntaxError(value string) error {
+	return &strconv.NumError{Func: "ParseNumber", Num: value, Err: strconv.ErrSyntax}
+}
+
+func numberRangeError(value string) error {
+	return &strconv.NumError{Func: "ParseNumber", Num: value, Err: strconv.ErrRange}
+}
+
+// normalizeNumber converts a formatted number like "$1,234.5k" to the strconv format and a unit multiplier
+func normalizeNumber(value string, format NumberFormat) (string, float64, error) {
+	s := strings.Map(func(ch rune) rune {
+		if unicode.Is(unicode.Sc, ch) {
+			return -1
+		}
+		return ch
+	}, value)
+	s = strings.TrimFunc(s, isNumberSpace)
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "\u2212") {
+		sign = "-"
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "\u2212")
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	end := strings.IndexFunc(s, func(ch rune) bool {
+		return !isNumberRune(ch)
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	digits := strings.TrimFunc(s[:end], isNumberSpace)
+	unit := strings.TrimFunc(s[end:], isNumberSpace)
+	multiplier, has := numberUnits[unit]
+	if !has {
+		multiplier, has = numberUnits[strings.ToUpper(unit)]
+	}
+	if !has || len(digits) == 0 {
+		return "", 0, numberSyntaxError(value)
+	}
+	separators, has := numberLocales[format]
+	if !has {
+		separators = guessSeparators(digits)
+	}
+	res := sign
+	for _, ch := range digits {
+		switch {
+		case ch >= '0' && ch <= '9':
+			res += string(ch)
+		case ch == separators.decimal:
+			res += "."
+		case ch == separators.group || ch == '\'' || isNumberSpace(ch):
+		default:
+			return "", 0, numberSyntaxError(value)
+		}
+	}
+	return res, multiplier, nil
+}
+
+func guessSeparators(digits string) numberSeparators {
+	lastDot := strings.LastIndex(digits, ".")
+	lastComma := strings.LastIndex(digits, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastDot > lastComma {
+			return numberLocales[NumberEN]
+		}
+		return numberLocales[NumberDE]
+	case lastDot < 0 && lastComma < 0:
+		return numberLocales[NumberEN]
+	}
+	separator := ','
+	last := lastComma
+	if lastDot >= 0 {
+		separator = '.'
+		last = lastDot
+	}
+	isGroup := strings.Count(digits, string(separator)) > 1 ||
+		len(digits)-last-1 == 3 && strings.Trim(digits[:last], "0") != ""
+	if isGroup == (separator == ',') {
+		return numberLocales[NumberEN]
+	}
+	return numberLocales[NumberDE]
+}
+
+func parseNumber(value string, format NumberFormat, valueField reflect.Value) error {
+	number, multiplier, err := normalizeNumber(value, format)
+	if err != nil {
+		return err
+	}
+	isInt := valueField.Kind() >= reflect.Int && valueField.Kind() <= reflect.Int64
+	if multiplier == 1 && isInt {
+		return parseInt(number, valueField)
+	}
+	if multiplier == 1 && !isInt && valueField.Kind() != reflect.Float32 && valueField.Kind() != reflect.Float64 {
+		return parseUint(number, valueField)
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return err
+	}
+	f *= multiplier
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return numberRangeError(value)
+	}
+	rounded := math.Round(f)
+	switch {
+	case valueField.Kind() == reflect.Float32 || valueField.Kind() == reflect.Float64:
+		if valueField.OverflowFloat(f) {
+			return numberRangeError(value)
+		}
+		valueField.SetFloat(f)
+	case isInt:
+		if rounded < math.MinInt64 || rounded >= math.MaxInt64 || valueField.OverflowInt(int64(rounded)) {
+			return numberRangeError(value)
+		}
+		valueField.SetInt(int64(rounded))
+	case f < 0:
+		return numberSyntaxError(value)
+	default:
+		if rounded >= math.MaxUint64 || valueField.OverflowUint(uint64(rounded)) {
+			return numberRangeError(value)
+		}
+		valueField.SetUint(uint64(rounded))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package gordom
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		value      string
+		format     NumberFormat
+		number     string
+		multiplier float64
+	}{
+		{"1,234", NumberEN, "1234", 1},
+		{"1,234.56", NumberEN, "1234.56", 1},
+		{"1.234,56", NumberDE, "1234.56", 1},
+		{"1 234,56", NumberFR, "1234.56", 1},
+		{"1 234,56 €", NumberFR, "1234.56", 1},
+		{"1'234.56", NumberCH, "1234.56", 1},
+		{"$12.50", NumberEN, "12.50", 1},
+		{"-$12.50", NumberEN, "-12.50", 1},
+		{"1.2k", NumberEN, "1.2", 1e3},
+		{"3.4M", NumberEN, "3.4", 1e6},
+		{"1.5 GB", NumberEN, "1.5", 1e9},
+		{"2 KiB", NumberEN, "2", 1024},
+		{"1,234.56", NumberAuto, "1234.56", 1},
+		{"1.234,56", NumberAuto, "1234.56", 1},
+		{"1.234", NumberAuto, "1234", 1},
+		{"1,5", NumberAuto, "1.5", 1},
+		{"0,123", NumberAuto, "0.123", 1},
+		{"1.234.567", NumberAuto, "1234567", 1},
+	}
+	for _, test := range tests {
+		number, multiplier, err := normalizeNumber(test.value, test.format)
+		assert.Nil(t, err, test.value)
+		assert.Equal(t, test.number, number, test.value)
+		assert.Equal(t, test.multiplier, multiplier, test.value)
+	}
+}
+
+func TestNormalizeNumberError(t *testing.T) {
+	for _, value := range []string{"", "$", "1,234 commits", "abc", "1.2.3x"} {
+		_, _, err := normalizeNumber(value, NumberEN)
+		assert.True(t, errors.Is(err, strconv.ErrSyntax), value)
+	}
+}
+
+type Numbers struct {
+	Price    float64 `$:".price" number:"en"`
+	PriceDE  float64 `$:".price-de" number:"de"`
+	Views    int     `$:".views" number:"en"`
+	Size     uint64  `$:".size" number:"en"`
+	Counter  int     `$:".counter"`
+	Strict   int     `$:".strict" number:"strict"`
+	Negative int     `$:".negative"`
+}
+
+func TestParseNumbers(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<span class="price">$1,212.50</span>
+		<span class="price-de">1.212,50 €</span>
+		<span class="views">3.4M</span>
+		<span class="size">1.5 GB</span>
+		<span class="counter">1.234</span>
+		<span class="strict">12</span>
+		<span class="negative">-1,5k</span>
+	</body>
+	`
+	n := &Numbers{}
+	err := Parse(html, n, WithNumberFormat(NumberDE))
+	assert.Nil(t, err)
+	assert.Equal(t, 1212.5, n.Price)
+	assert.Equal(t, 1212.5, n.PriceDE)
+	assert.Equal(t, 3400000, n.Views)
+	assert.Equal(t, uint64(1500000000), n.Size)
+	assert.Equal(t, 1234, n.Counter)
+	assert.Equal(t, 12, n.Strict)
+	assert.Equal(t, -1500, n.Negative)
+}
+
+func TestParseNumbersStrictByDefault(t *testing.T) {
+	html := `<html><body><span class="counter">1.234</span></body></html>`
+	n := &Numbers{}
+	err := Parse(html, n)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+type SmallNumbers struct {
+	Int8  int8    `$:".int8"`
+	Uint8 uint8   `$:".uint8"`
+	Float float32 `$:".float"`
+}
+
+func smallNumbersHtml(int8, uint8, float string) string {
+	return fmt.Sprintf(`<html><body><span class="int8">%s</span><span class="uint8">%s</span>
+		<span class="float">%s</span></body></html>`, int8, uint8, float)
+}
+
+func TestParseNumbersOverflow(t *testing.T) {
+	for _, html := range []string{
+		smallNumbersHtml("1k", "1", "1"),
+		smallNumbersHtml("1,000", "1", "1"),
+		smallNumbersHtml("1", "256", "1"),
+		smallNumbersHtml("1", "1", "1,000,000,000,000,000,000,000,000,000,000,000G"),
+	} {
+		err := Parse(html, &SmallNumbers{}, WithNumberFormat(NumberEN))
+		assert.True(t, errors.Is(err, strconv.ErrRange), html)
+	}
+	n := &SmallNumbers{}
+	err := Parse(smallNumbersHtml("-128", "0.255k", "1.5k"), n, WithNumberFormat(NumberEN))
+	assert.Nil(t, err)
+	assert.Equal(t, SmallNumbers{Int8: -128, Uint8: 255, Float: 1500}, *n)
+}
+
+type InvalidNumberFormat struct {
+	Counter int `number:"xx"`
+}
+
+func TestParseInvalidNumberFormat(t *testing.T) {
+	html := `<html><body>1</body></html>`
+	assert.True(t, errors.Is(Parse(html, &InvalidNumberFormat{}), ErrInvalidTag))
+	assert.NotNil(t, Parse(html, &Int{}, WithNumberFormat("xx")))
+}
@@ -0,0 +1,180 @@
+package gordom
+
+import (
+	"net/http"
+	"reflect"
+)
+
+type Option func(*options)
+
+type options struct {
+	charset       string
+	client        *http.Client
+	converters    map[reflect.Type]Converter
+	followDepth   int
+	followLimit   int
+	header        http.Header
+	lenient       bool
+	maxPages      int
+	nextPage      string
+	normalizeText func(string) string
+	numberFormat  NumberFormat
+	tags          tagNames
+	url           string
+	absoluteURLs  bool
+}
+
+type tagNames struct {
+	selector string
+	value    string
+}
+
+var defaultTags = tagNames{
+	selector: "$",
+	value:    "value",
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		client:       http.DefaultClient,
+		followLimit:  defaultFollowLimit,
+		numberFormat: NumberStrict,
+		tags:         defaultTags,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCharset sets the charset of html, e.g. "windows-1251", instead of detecting it
+func WithCharset(label string) Option {
+	return func(o *options) {
+		o.charset = label
+	}
+}
+
+// WithConverter sets a converter for fields of the same type as sample.
+// Converters take precedence over built-in conversions and Unmarshaler.
+func WithConverter(sample interface{}, converter Converter) Option {
+	return func(o *options) {
+		if o.converters == nil {
+			o.converters = map[reflect.Type]Converter{}
+		}
+		o.converters[reflect.TypeOf(sample)] = converter
+	}
+}
+
+// WithAbsoluteURLs rewrites link attributes like href and src of the whole document to absolute urls
+// before decoding. The document passed to ParseDocument is changed.
+func WithAbsoluteURLs() Option {
+	return func(o *options) {
+		o.absoluteURLs = true
+	}
+}
+
+// WithBasicAuth sets the Authorization header of requests
+func WithBasicAuth(username string, password string) Option {
+	return WithHeader("Authorization", basicAuth(username, password))
+}
+
+// WithBearerToken sets the Authorization header of requests
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithFollowDepth sets how deep follow fields follow links from the fetched page, e.g. 1 follows links
+// of the page but not links of followed pages. Links are followed at any depth by default.
+func WithFollowDepth(n int) Option {
+	return func(o *options) {
+		o.followDepth = n
+	}
+}
+
+// WithFollowLimit sets the number of pages fetched concurrently by follow fields, 4 by default
+func WithFollowLimit(n int) Option {
+	return func(o *options) {
+		o.followLimit = n
+	}
+}
+
+// WithHeader sets the header of requests
+func WithHeader(key string, value string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// WithHTTPClient sets the client used to fetch pages
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithLenient makes parsing continue after field errors. All fields which can be set are filled
+// and FieldErrors with an error for every failed field is returned.
+func WithLenient() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// WithMaxPages limits the number of pages fetched by following next pages
+func WithMaxPages(n int) Option {
+	return func(o *options) {
+		o.maxPages = n
+	}
+}
+
+// WithNextPage sets the selector of the next page link like the next tag, e.g. "a.next" or "a[rel=next]|[href]"
+func WithNextPage(selector string) Option {
+	return func(o *options) {
+		o.nextPage = selector
+	}
+}
+
+// WithNumberFormat sets the number format for numeric fields without the number tag.
+func WithNumberFormat(format NumberFormat) Option {
+	return func(o *options) {
+		o.numberFormat = format
+	}
+}
+
+// WithSelectorTag sets the name of the tag with a css selector, "$" by default
+func WithSelectorTag(name string) Option {
+	return func(o *options) {
+		o.tags.selector = name
+	}
+}
+
+// WithTextNormalizer sets a function applied to every extracted value before transforms,
+// e.g. strings.TrimSpace or NormalizeSpace.
+func WithTextNormalizer(normalize func(string) string) Option {
+	return func(o *options) {
+		o.normalizeText = normalize
+	}
+}
+
+// WithURL sets the url of the document, which is used to resolve relative urls. Fetching functions
+// use the url of the fetched page instead.
+func WithURL(rawURL string) Option {
+	return func(o *options) {
+		o.url = rawURL
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithValueTag sets the name of the tag with a value source, "value" by default
+func WithValueTag(name string) Option {
+	return func(o *options) {
+		o.tags.value = name
+	}
+}
@@ -0,0 +1,130 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"net/url"
+	"reflect"
+)
+
+// compileNext compiles the next tag or the WithNextPage option. The link is taken from [href] by default.
+func compileNext(tag string) (*fieldPlan, error) {
+	selector, rest := splitSelector(tag)
+	if rest == "" {
+		rest = "[href]"
+	}
+	if selector == "" {
+		return nil, fmt.Errorf("%w: next:%q", ErrInvalidTag, tag)
+	}
+	return compileKey(selector + "|" + rest)
+}
+
+// compileStructNext looks for the next tag in all fields of the struct, including blank fields like
+// _ struct{} `next:"a.next"`
+func compileStructNext(t reflect.Type) (*fieldPlan, error) {
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		if tag, has := typeField.Tag.Lookup("next"); has {
+			next, err := compileNext(tag)
+			if err != nil {
+				return nil, withField(err, typeField.Name, "next", tag)
+			}
+			return next, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *Decoder) nextPlan(plan *typePlan) (*fieldPlan, error) {
+	if d.opts.nextPage == "" {
+		return plan.next, nil
+	}
+	return compileNext(d.opts.nextPage)
+}
+
+// nextURL returns the absolute url of the next page or nil if there is no next page
+func (d *Decoder) nextURL(doc *node.Document, next *fieldPlan) *url.URL {
+	if doc.Body == nil {
+		return nil
+	}
+	found := findOne(doc.Body, next)
+	if found == nil {
+		return nil
+	}
+	link, err := d.newParser().getValue(found, next)
+	if err != nil || link == "" {
+		return nil
+	}
+	u, err := doc.BaseURL().Parse(link)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	u.Fragment = ""
+	return u
+}
+
+// decodePages follows next pages after the first one. Slices and maps of the struct accumulate entries
+// of all pages, other fields keep values of the first page.
+func (f *fetcher) decodePages(doc *node.Document, value reflect.Value, plan *typePlan, next *fieldPlan, err error) error {
+	d := f.decoder
+	var errs FieldErrors
+	if err != nil {
+		errs = appendErrors(errs, err)
+	}
+	visited := map[string]bool{pageKey(doc.URL): true}
+	for pages := 1; d.opts.maxPages == 0 || pages < d.opts.maxPages; pages++ {
+		nextURL := d.nextURL(doc, next)
+		if nextURL == nil || visited[nextURL.String()] {
+			break
+		}
+		visited[nextURL.String()] = true
+
+		doc, err = d.fetchDocument(f.ctx, nextURL.String())
+		if err != nil {
+			return err
+		}
+		if pageURL := pageKey(doc.URL); pageURL != nextURL.String() {
+			if visited[pageURL] {
+				break
+			}
+			visited[pageURL] = true
+		}
+		page := reflect.New(value.Type())
+		if err := f.decodePage(doc, page.Interface()); err != nil {
+			if !d.opts.lenient {
+				return err
+			}
+			errs = appendErrors(errs, err)
+		}
+		mergePage(value, page.Elem(), plan)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func pageKey(pageURL *url.URL) string {
+	u := *pageURL
+	u.Fragment = ""
+	return u.String()
+}
+
+func mergePage(value reflect.Value, page reflect.Value, plan *typePlan) {
+	for _, field := range plan.fields {
+		dst := fieldByIndex(value, field.index)
+		src := fieldByIndex(page, field.index)
+		switch field.typ.kind {
+		case sliceKind:
+			dst.Set(reflect.AppendSlice(dst, src))
+		case mapKind:
+			if dst.IsNil() {
+				dst.Set(src)
+				continue
+			}
+			for _, key := range src.MapKeys() {
+				dst.SetMapIndex(key, src.MapIndex(key))
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package gordom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type Listing struct {
+	_     struct{}       `next:"a.next"`
+	Title string         `$:"h1"`
+	Items []string       `$:".item"`
+	Ids   map[string]int `$:".item" key:"text" value:"[data-id]"`
+}
+
+// pagesServer serves pages /1 ... /n with two items each, links are relative
+func pagesServer(n int, next func(page int) string) (*httptest.Server, *int32) {
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		page := 0
+		fmt.Sscanf(r.URL.Path, "/%d", &page)
+		if page < 1 || page > n {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		b := &strings.Builder{}
+		fmt.Fprintf(b, `<html><body><h1>Page %d</h1>`, page)
+		for i := 1; i <= 2; i++ {
+			fmt.Fprintf(b, `<li class="item" data-id="%d%d">item %d.%d</li>`, page, i, page, i)
+		}
+		if link := next(page); link != "" {
+			fmt.Fprintf(b, `<a class="next" href="%s">Next</a>`, link)
+		}
+		b.WriteString(`</body></html>`)
+		fmt.Fprint(w, b.String())
+	}))
+	return server, &requests
+}
+
+func TestParsePages(t *testing.T) {
+	server, requests := pagesServer(3, func(page int) string {
+		if page == 3 {
+			return ""
+		}
+		return fmt.Sprintf("%d#items", page+1)
+	})
+	defer server.Close()
+
+	listing := &Listing{}
+	err := ParseFromURLContext(context.Background(), server.URL+"/1", listing)
+	assert.Nil(t, err)
+	assert.Equal(t, "Page 1", listing.Title)
+	assert.Equal(t, []string{"item 1.1", "item 1.2", "item 2.1", "item 2.2", "item 3.1", "item 3.2"}, listing.Items)
+	assert.Equal(t, 6, len(listing.Ids))
+	assert.Equal(t, 32, listing.Ids["item 3.2"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestParsePagesLoop(t *testing.T) {
+	server, requests := pagesServer(3, func(page int) string {
+		return fmt.Sprintf("/%d", page%3+1)
+	})
+	defer server.Close()
+
+	listing := &Listing{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/1", listing))
+	assert.Equal(t, 6, len(listing.Items))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestParsePagesRedirectLoop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1":
+			fmt.Fprint(w, `<html><body><li class="item" data-id="1">item 1</li><a class="next" href="/old/2">Next</a></body></html>`)
+		case "/2":
+			fmt.Fprint(w, `<html><body><li class="item" data-id="2">item 2</li><a class="next" href="/old/1">Next</a></body></html>`)
+		default:
+			http.Redirect(w, r, strings.TrimPrefix(r.URL.Path, "/old"), http.StatusMovedPermanently)
+		}
+	}))
+	defer server.Close()
+
+	listing := &Listing{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/1", listing))
+	assert.Equal(t, []string{"item 1", "item 2"}, listing.Items)
+}
+
+func TestParsePagesMax(t *testing.T) {
+	server, requests := pagesServer(10, func(page int) string {
+		return fmt.Sprintf("/%d", page+1)
+	})
+	defer server.Close()
+
+	listing := &Listing{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/1", listing, WithMaxPages(2)))
+	assert.Equal(t, 4, len(listing.Items))
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestParsePagesOption(t *testing.T) {
+	server, _ := pagesServer(2, func(page int) string {
+		return fmt.Sprintf("/%d", page+1)
+	})
+	defer server.Close()
+
+	type Items struct {
+		Items []string `$:".item"`
+	}
+	items := &Items{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/1", items))
+	assert.Equal(t, 2, len(items.Items))
+
+	err := ParseFromURLContext(context.Background(), server.URL+"/1", items, WithNextPage("a.next"))
+	statusErr := &StatusError{}
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusNotFound, statusErr.Response.StatusCode)
+}
+
+func TestParsePagesBase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/list/":
+			fmt.Fprint(w, `<html><head><base href="/pages/"></head><body><li class="item" data-id="1">a</li><a class="next" href="2">Next</a></body></html>`)
+		case "/pages/2":
+			fmt.Fprint(w, `<html><body><li class="item" data-id="2">b</li><a class="next" href="javascript:void(0)">Next</a></body></html>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	listing := &Listing{}
+	assert.Nil(t, ParseFromURLContext(context.Background(), server.URL+"/list/", listing))
+	assert.Equal(t, []string{"a", "b"}, listing.Items)
+}
+
+type InvalidNext struct {
+	_ struct{} `next:"[href]"`
+}
+
+func TestCompileNext(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&InvalidNext{}), ErrInvalidTag))
+	next, err := compileNext("a.next[href]")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"[href]"}, next.sources)
+	next, err = compileNext("a[rel=next]|[data-url]|trim")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"[data-url]"}, next.sources)
+}
@@ -1,43 +1,51 @@
 package gordom
 
 import (
-	"errors"
+	"context"
 	"github.com/lucky-libora/gordom/node"
 	"io"
-	"net/http"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
-func getValue(n *node.Node, typeField reflect.StructField) string {
-	if n == nil {
-		return ""
-	}
-	valueTag := typeField.Tag.Get("value")
-	if strings.HasPrefix(valueTag, "[") && strings.HasSuffix(valueTag, "]") {
-		attrKey := strings.Trim(valueTag, "[]")
-		return n.Attrs[attrKey]
-	}
-	return n.InnerText()
+type parser struct {
+	opts    options
+	fetcher *fetcher
+	base    *url.URL
+	depth   int
 }
 
-func findMany(n *node.Node, typeField reflect.StructField) []*node.Node {
-	query := typeField.Tag.Get("$")
-	if len(query) > 0 {
-		return n.Select(query)
-
+// findMany returns the node and its descendants matching the field selector or values of the field label,
+// or the node itself if there is neither.
+// Selectors separated by || are tried in order until one of them matches.
+func findMany(n *node.Node, field *fieldPlan) []*node.Node {
+	if field.label != "" {
+		return findLabeled(n, field)
+	}
+	if len(field.queries) == 0 {
+		return []*node.Node{n}
+	}
+	for _, q := range field.queries {
+		if found := q.findAll(n); len(found) > 0 {
+			return found
+		}
 	}
-	return []*node.Node{n}
+	return nil
 }
 
-func findOne(node *node.Node, typeField reflect.StructField) *node.Node {
-	query := typeField.Tag.Get("$")
-	if len(query) > 0 {
-		return node.SelectOne(query)
-
+func findOne(n *node.Node, field *fieldPlan) *node.Node {
+	if field.label != "" {
+		if found := findLabeled(n, field); len(found) > 0 {
+			return found[0]
+		}
+		return nil
+	}
+	if len(field.queries) == 0 {
+		return n
 	}
-	return node
+	return findFirst(n, field.queries)
 }
 
 func parseFloat(value string, valueField reflect.Value) error {
@@ -50,7 +58,7 @@ func parseFloat(value string, valueField reflect.Value) error {
 }
 
 func parseInt(value string, valueField reflect.Value) error {
-	i, err := strconv.ParseInt(value, 10, 64)
+	i, err := strconv.ParseInt(value, 10, valueField.Type().Bits())
 	if err != nil {
 		return err
 	}
@@ -58,33 +66,99 @@ func parseInt(value string, valueField reflect.Value) error {
 	return nil
 }
 
-func parseSlice(node *node.Node, typeField reflect.StructField, valueField reflect.Value) error {
-	slice := reflect.MakeSlice(typeField.Type, 1, 1)
-	sliceType := slice.Index(0).Type()
-	slice = reflect.MakeSlice(typeField.Type, 0, 0)
-	found := findMany(node, typeField)
-	for _, n := range found {
-		value, err := createNewStruct(n, sliceType)
+func parseAttrs(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	valueField.Set(getAttrs(found, field, valueField.Type()))
+	return nil
+}
+
+func (p *parser) parseCount(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findManyChecked(node, field)
+	if err != nil {
+		return err
+	}
+	return p.parseScalar(strconv.Itoa(len(found)), field, valueField)
+}
+
+func (p *parser) parsePtr(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	ptr, err := p.createNewPointer(found, field, field.typ)
+	if err != nil && !p.opts.lenient {
+		return err
+	}
+	valueField.Set(ptr)
+	return err
+}
+
+func (p *parser) parseScalar(value string, field *fieldPlan, valueField reflect.Value) error {
+	if isNumberKind(valueField.Kind()) {
+		format, err := p.numberFormat(field)
 		if err != nil {
 			return err
 		}
+		if format != NumberStrict {
+			return newValueError(value, parseNumber(value, format, valueField))
+		}
+	}
+	var err error
+	switch valueField.Kind() {
+	case reflect.Float32, reflect.Float64:
+		err = parseFloat(value, valueField)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		err = parseInt(value, valueField)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		err = parseUint(value, valueField)
+	case reflect.String:
+		valueField.SetString(value)
+	}
+	return newValueError(value, err)
+}
+
+func (p *parser) parseSlice(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	slice := reflect.MakeSlice(field.typ.t, 0, 0)
+	found, err := findManyChecked(node, field)
+	if err != nil {
+		return err
+	}
+	var errs FieldErrors
+	for i, n := range found {
+		value, err := p.createNewValue(n, field, field.typ.elem)
+		if err != nil {
+			if !p.opts.lenient {
+				return withIndex(err, i)
+			}
+			errs = appendErrors(errs, withIndex(err, i))
+		}
 		slice = reflect.Append(slice, value)
 	}
 	valueField.Set(slice)
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
-func parseStruct(node *node.Node, typeField reflect.StructField, valueField reflect.Value) error {
-	str, err := createNewStruct(node, typeField.Type)
-	if err != nil {
+func (p *parser) parseStruct(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	str, err := p.createNewStruct(found, field.typ)
+	if err != nil && !p.opts.lenient {
 		return err
 	}
 	valueField.Set(str)
-	return nil
+	return err
 }
 
 func parseUint(value string, valueField reflect.Value) error {
-	i, err := strconv.ParseUint(value, 10, 64)
+	i, err := strconv.ParseUint(value, 10, valueField.Type().Bits())
 	if err != nil {
 		return err
 	}
@@ -92,80 +166,157 @@ func parseUint(value string, valueField reflect.Value) error {
 	return nil
 }
 
-func parseValue(node *node.Node, typeField reflect.StructField, valueField reflect.Value) error {
-	kind := typeField.Type.Kind()
-	switch kind {
-	case reflect.Slice:
-		return parseSlice(node, typeField, valueField)
-	case reflect.Struct:
-		return parseStruct(node, typeField, valueField)
+func (p *parser) parseValue(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	if field.count {
+		return p.parseCount(node, field, valueField)
+	}
+	if field.follow != nil {
+		return p.parseFollow(node, field, valueField)
+	}
+	if converter := p.opts.converters[field.typ.t]; converter != nil {
+		return p.parseCustom(node, field, valueField, converter)
+	}
+	switch field.typ.kind {
+	case convertedKind:
+		return p.parseConverted(node, field, valueField)
+	case unmarshalerKind:
+		return p.parseUnmarshaler(node, field, valueField)
+	case attrsKind:
+		return parseAttrs(node, field, valueField)
+	case sliceKind:
+		return p.parseSlice(node, field, valueField)
+	case mapKind:
+		return p.parseMap(node, field, valueField)
+	case structKind:
+		return p.parseStruct(node, field, valueField)
+	case ptrKind:
+		return p.parsePtr(node, field, valueField)
 	default:
-		value := getValue(findOne(node, typeField), typeField)
-		switch kind {
-		case reflect.Float32, reflect.Float64:
-			return parseFloat(value, valueField)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return parseInt(value, valueField)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			return parseUint(value, valueField)
-		case reflect.String:
-			valueField.SetString(value)
+		found, err := findChecked(node, field)
+		if err != nil {
+			return err
+		}
+		value, err := p.getValue(found, field)
+		if err != nil {
+			return err
 		}
+		return p.parseScalar(value, field, valueField)
 	}
-	return nil
 }
 
-func setFields(node *node.Node, value reflect.Value, t reflect.Type) (reflect.Value, error) {
-	for i := 0; i < value.NumField(); i++ {
-		typeField := t.Field(i)
-		valueField := value.Field(i)
-		err := parseValue(node, typeField, valueField)
+func (p *parser) setFields(node *node.Node, value reflect.Value, plan *typePlan) (reflect.Value, error) {
+	var errs FieldErrors
+	for _, field := range plan.fields {
+		valueField := fieldByIndex(value, field.index)
+		err := p.parseValue(node, field, valueField)
 		if err != nil {
-			return value, err
+			err = withField(err, field.name, p.opts.tags.selector, field.selector)
+			if !p.opts.lenient {
+				return value, err
+			}
+			errs = appendErrors(errs, err)
 		}
 	}
+	if len(errs) > 0 {
+		return value, errs
+	}
 	return value, nil
 }
 
-func createNewStruct(node *node.Node, t reflect.Type) (reflect.Value, error) {
-	value := reflect.New(t).Elem()
-	return setFields(node, value, t)
+// fieldByIndex returns the nested field by index like reflect.Value.FieldByIndex,
+// but allocates nil pointers to embedded structs
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+func (p *parser) createNewStruct(node *node.Node, plan *typePlan) (reflect.Value, error) {
+	value := reflect.New(plan.t).Elem()
+	return p.setFields(node, value, plan)
 }
 
-func parseByType(node *node.Node, ptr interface{}) error {
-	if reflect.ValueOf(ptr).Kind() != reflect.Ptr {
-		return errors.New("pointer to struct should be passed")
+func (p *parser) createNewPointer(node *node.Node, field *fieldPlan, plan *typePlan) (reflect.Value, error) {
+	ptr := reflect.New(plan.elem.t)
+	value, err := p.createNewValue(node, field, plan.elem)
+	ptr.Elem().Set(value)
+	return ptr, err
+}
+
+func (p *parser) createNewValue(node *node.Node, field *fieldPlan, plan *typePlan) (reflect.Value, error) {
+	if plan.kind == ptrKind {
+		return p.createNewPointer(node, field, plan)
+	}
+	value := reflect.New(plan.t).Elem()
+	if converter := p.opts.converters[plan.t]; converter != nil {
+		return value, p.customConvert(node, field, value, converter)
 	}
-	value := reflect.Indirect(reflect.ValueOf(ptr))
-	t := value.Type()
+	switch plan.kind {
+	case convertedKind:
+		return value, p.convert(node, field, value)
+	case unmarshalerKind:
+		_, err := p.unmarshal(node, field, value)
+		return value, err
+	case structKind:
+		return p.setFields(node, value, plan)
+	case attrsKind:
+		return getAttrs(node, field, plan.t), nil
+	}
+	text, err := p.getValue(node, field)
+	if err != nil {
+		return value, err
+	}
+	return value, p.parseScalar(text, field, value)
+}
+
+func (p *parser) parseByType(node *node.Node, ptr interface{}) error {
+	ptrValue := reflect.ValueOf(ptr)
+	if ptrValue.Kind() != reflect.Ptr || ptrValue.IsNil() {
+		return ErrNotPointer
+	}
+	value := ptrValue.Elem()
 	if value.Kind() != reflect.Struct {
-		return errors.New("pointer to struct should be passed")
+		return ErrNotStruct
 	}
-	_, err := setFields(node, value, t)
+	plan, err := structPlanOf(value.Type(), p.opts.tags)
+	if err != nil {
+		return err
+	}
+	_, err = p.setFields(node, value, plan)
 	return err
 }
 
-func parseDocument(doc *node.Document, ptr interface{}) error {
-	return parseByType(doc.Body, ptr)
+func Parse(html string, ptr interface{}, opts ...Option) error {
+	return ParseReader(strings.NewReader(html), ptr, opts...)
 }
 
-func Parse(html string, ptr interface{}) error {
-	return ParseReader(strings.NewReader(html), ptr)
+func ParseReader(reader io.Reader, ptr interface{}, opts ...Option) error {
+	return decoderOf(opts).Decode(reader, ptr)
 }
 
-func ParseReader(reader io.Reader, ptr interface{}) error {
-	doc := node.ParseHtml(reader)
-	return parseDocument(doc, ptr)
+// ParseNode maps the node and its descendants to the struct, so a parsed tree can be reused for several structs
+func ParseNode(n *node.Node, ptr interface{}, opts ...Option) error {
+	return decoderOf(opts).DecodeNode(n, ptr)
 }
 
-func ParseFromUrl(url string, ptr interface{}) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	err = ParseReader(resp.Body, ptr)
-	if err != nil {
-		return err
-	}
-	return resp.Body.Close()
+// ParseDocument maps the body of the document to the struct
+func ParseDocument(doc *node.Document, ptr interface{}, opts ...Option) error {
+	return decoderOf(opts).DecodeDocument(doc, ptr)
+}
+
+func ParseFromUrl(url string, ptr interface{}, opts ...Option) error {
+	return decoderOf(opts).DecodeURL(url, ptr)
+}
+
+// ParseFromURLContext fetches the page with the context and maps it to the struct.
+// StatusError is returned for non-2xx statuses.
+func ParseFromURLContext(ctx context.Context, url string, ptr interface{}, opts ...Option) error {
+	return decoderOf(opts).DecodeURLContext(ctx, url, ptr)
 }
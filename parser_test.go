@@ -1,7 +1,10 @@
 package gordom
 
 import (
+	"errors"
+	"github.com/lucky-libora/gordom/node"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -308,6 +311,213 @@ func TestParseArray(t *testing.T) {
 	}
 }
 
+type Pointers struct {
+	Text    *string  `$:"#text"`
+	Missing *string  `$:"#missing"`
+	Int     *int     `$:"#int"`
+	Image   *Image   `$:"img"`
+	NoImage *Image   `$:"#missing"`
+	Images  []*Image `$:"img"`
+}
+
+func TestParsePointers(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div id="main" class="main test">
+			<img src="img1.png"/>
+			<img src="img2.png"/>
+		</div>
+		<div id="text"></div>
+		<div id="int">3</div>
+	</body>
+	`
+	p := &Pointers{}
+	err := Parse(html, p)
+	assert.Nil(t, err)
+	assert.NotNil(t, p.Text)
+	assert.Equal(t, "", *p.Text)
+	assert.Nil(t, p.Missing)
+	assert.Equal(t, 3, *p.Int)
+	assert.Equal(t, "img1.png", p.Image.Src)
+	assert.Nil(t, p.NoImage)
+	assert.Equal(t, 2, len(p.Images))
+	assert.Equal(t, "img2.png", p.Images[1].Src)
+}
+
+type PointerError struct {
+	Int *int `$:"#int"`
+}
+
+func TestParsePointerError(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div id="int">blabla</div>
+	</body>
+	`
+	p := &PointerError{}
+	err := Parse(html, p)
+	assert.NotNil(t, err)
+	assert.Nil(t, p.Int)
+}
+
+type NestedScope struct {
+	Image Image `$:"#second"`
+}
+
+func TestParseStructScope(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<img id="first" src="img1.png"/>
+		<img id="second" src="img2.png"/>
+	</body>
+	`
+	s := &NestedScope{}
+	err := Parse(html, s)
+	assert.Nil(t, err)
+	assert.Equal(t, "img2.png", s.Image.Src)
+}
+
+type ScalarSlices struct {
+	Tags   []string  `$:".tag"`
+	Links  []string  `$:"a" value:"[href]"`
+	Ints   []int     `$:".int"`
+	Floats []float64 `$:".int"`
+	Uints  []*uint   `$:".int"`
+}
+
+func TestParseScalarSlices(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<span class="tag">go</span>
+		<span class="tag">html</span>
+		<a href="/a">A</a>
+		<a href="/b">B</a>
+		<i class="int">1</i>
+		<i class="int">2</i>
+	</body>
+	`
+	s := &ScalarSlices{}
+	err := Parse(html, s)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go", "html"}, s.Tags)
+	assert.Equal(t, []string{"/a", "/b"}, s.Links)
+	assert.Equal(t, []int{1, 2}, s.Ints)
+	assert.Equal(t, []float64{1, 2}, s.Floats)
+	assert.Equal(t, uint(2), *s.Uints[1])
+}
+
+func TestParseScalarSliceError(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<i class="int">1</i>
+		<i class="int">two</i>
+	</body>
+	`
+	s := &ScalarSlices{}
+	err := Parse(html, s)
+	assert.NotNil(t, err)
+}
+
+type ValueSources struct {
+	HTML      string              `$:"#content" value:"html"`
+	OuterHTML string              `$:"#content b" value:"outerhtml"`
+	OwnText   string              `$:"#content" value:"owntext"`
+	Tag       string              `$:"#content b" value:"tag"`
+	Count     int                 `$:".item" value:"count"`
+	Attrs     map[string]string   `$:"#content"`
+	Data      map[string]string   `$:"#content" value:"data"`
+	NoAttrs   map[string]string   `$:"#missing"`
+	Items     []map[string]string `$:".item"`
+}
+
+func TestParseValueSources(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<div id="content" class="c" data-id="1" data-kind="post">Hello <b>world</b></div>
+		<i class="item" data-n="1"></i>
+		<i class="item" data-n="2"></i>
+	</body>
+	`
+	v := &ValueSources{}
+	err := Parse(html, v)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello<b>world</b>", v.HTML)
+	assert.Equal(t, "<b>world</b>", v.OuterHTML)
+	assert.Equal(t, "Hello", v.OwnText)
+	assert.Equal(t, "b", v.Tag)
+	assert.Equal(t, 2, v.Count)
+	assert.Equal(t, map[string]string{"id": "content", "class": "c", "data-id": "1", "data-kind": "post"}, v.Attrs)
+	assert.Equal(t, map[string]string{"id": "1", "kind": "post"}, v.Data)
+	assert.Nil(t, v.NoAttrs)
+	assert.Equal(t, "2", v.Items[1]["data-n"])
+}
+
+type Headline struct {
+	Title string `$:"h1"`
+}
+
+type Card struct {
+	Class string `$:".card" value:"[class]"`
+}
+
+func TestParseNode(t *testing.T) {
+	html := `
+	<body>
+		<div class="card"><h1>First</h1><img src="1.png"/></div>
+		<div class="card"><h1>Second</h1><img src="2.png"/></div>
+	</body>
+	`
+	doc := node.ParseHtml(strings.NewReader(html))
+	cards := doc.Select(".card")
+	assert.Equal(t, 2, len(cards))
+
+	headline := &Headline{}
+	assert.Nil(t, ParseNode(cards[1], headline))
+	assert.Equal(t, "Second", headline.Title)
+
+	card := &Card{}
+	assert.Nil(t, ParseNode(cards[1], card))
+	assert.Equal(t, "card", card.Class)
+
+	image := &Image{}
+	assert.Nil(t, ParseNode(cards[1].SelectOne("img"), image))
+	assert.Equal(t, "2.png", image.Src)
+
+	assert.Nil(t, ParseDocument(doc, headline))
+	assert.Equal(t, "First", headline.Title)
+	ic := &ImageCollection{}
+	assert.Nil(t, ParseDocument(doc, ic))
+	assert.Equal(t, 2, len(ic.Images))
+
+	assert.True(t, errors.Is(ParseNode(nil, headline), ErrNilNode))
+	assert.True(t, errors.Is(ParseDocument(nil, headline), ErrNilNode))
+	assert.True(t, errors.Is(ParseNode(cards[0], *headline), ErrNotPointer))
+}
+
 type GitHubProjectFile struct {
 	Link string `value:"[href]"`
 	Name string
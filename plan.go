@@ -0,0 +1,397 @@
+package gordom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type planKind int
+
+const (
+	scalarKind planKind = iota
+	attrsKind
+	convertedKind
+	unmarshalerKind
+	ptrKind
+	sliceKind
+	mapKind
+	structKind
+)
+
+// typePlan describes how a value of the type is decoded from a node.
+// Plans are built once per type and shared between goroutines, so they must not be changed after compilation.
+type typePlan struct {
+	kind   planKind
+	t      reflect.Type
+	elem   *typePlan
+	fields []*fieldPlan
+	next   *fieldPlan
+}
+
+// fieldPlan keeps parsed tags and a compiled selector of a struct field
+type fieldPlan struct {
+	name         string
+	index        []int
+	selector     string
+	queries      []query
+	label        string
+	sources      []string
+	transforms   []transformStep
+	defaultValue string
+	hasDefault   bool
+	hasValueTag  bool
+	count        bool
+	dataAttrs    bool
+	required     bool
+	limits       []countLimit
+	numberFormat NumberFormat
+	boolPresent  bool
+	truthy       []string
+	hasTruthy    bool
+	layouts      []string
+	key          *fieldPlan
+	valueQueries []query
+	follow       *fieldPlan
+	recursive    bool
+	typ          *typePlan
+}
+
+type planKey struct {
+	t    reflect.Type
+	tags tagNames
+}
+
+var plans = sync.Map{}
+
+// Compile builds and caches the decoding plan of the struct type, so schema errors like invalid tags
+// can be checked at startup. It accepts a struct or a pointer to struct.
+func Compile(v interface{}) error {
+	return defaultDecoder.Compile(v)
+}
+
+func compileType(v interface{}, tags tagNames) error {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	_, err := structPlanOf(t, tags)
+	return err
+}
+
+func structPlanOf(t reflect.Type, tags tagNames) (*typePlan, error) {
+	if cached, has := plans.Load(planKey{t: t, tags: tags}); has {
+		return cached.(*typePlan), nil
+	}
+	c := &planCompiler{plans: map[reflect.Type]*typePlan{}, tags: tags}
+	plan, err := c.compileStruct(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range c.plans {
+		if err := markRecursive(p, tags); err != nil {
+			return nil, err
+		}
+	}
+	for t, p := range c.plans {
+		plans.LoadOrStore(planKey{t: t, tags: tags}, p)
+	}
+	cached, _ := plans.Load(planKey{t: t, tags: tags})
+	if cached == nil {
+		return plan, nil
+	}
+	return cached.(*typePlan), nil
+}
+
+type planCompiler struct {
+	plans map[reflect.Type]*typePlan
+	tags  tagNames
+}
+
+func (c *planCompiler) compileStruct(t reflect.Type) (*typePlan, error) {
+	if plan, has := c.plans[t]; has {
+		return plan, nil
+	}
+	if cached, has := plans.Load(planKey{t: t, tags: c.tags}); has {
+		return cached.(*typePlan), nil
+	}
+	plan := &typePlan{kind: structKind, t: t}
+	c.plans[t] = plan
+	next, err := compileStructNext(t)
+	if err != nil {
+		return nil, err
+	}
+	plan.next = next
+	for _, typeField := range structFields(t, c.tags.selector) {
+		field, err := c.compileField(typeField)
+		if err != nil {
+			return nil, withField(err, typeField.Name, c.tags.selector, typeField.Tag.Get(c.tags.selector))
+		}
+		plan.fields = append(plan.fields, field)
+	}
+	return plan, nil
+}
+
+type fieldCandidate struct {
+	typeField reflect.StructField
+	depth     int
+}
+
+// structFields returns fields to be set, including fields promoted from embedded structs.
+// As in Go, a field hides fields with the same name from deeper embedded structs,
+// and fields with the same name at the same depth hide each other.
+// Unexported fields and fields tagged by $:"-" are skipped.
+func structFields(t reflect.Type, selectorTag string) []reflect.StructField {
+	var candidates []fieldCandidate
+	collectFields(t, nil, 0, selectorTag, map[reflect.Type]bool{}, &candidates)
+
+	depths := map[string]int{}
+	counts := map[string]int{}
+	for _, candidate := range candidates {
+		name := candidate.typeField.Name
+		depth, has := depths[name]
+		switch {
+		case !has || candidate.depth < depth:
+			depths[name] = candidate.depth
+			counts[name] = 1
+		case candidate.depth == depth:
+			counts[name]++
+		}
+	}
+
+	var fields []reflect.StructField
+	for _, candidate := range candidates {
+		name := candidate.typeField.Name
+		if candidate.depth == depths[name] && counts[name] == 1 {
+			fields = append(fields, candidate.typeField)
+		}
+	}
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, depth int, selectorTag string, visited map[reflect.Type]bool, candidates *[]fieldCandidate) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		if typeField.Tag.Get(selectorTag) == "-" {
+			continue
+		}
+		typeField.Index = append(append([]int{}, index...), i)
+		if isEmbeddedStruct(typeField, selectorTag) {
+			embedded := typeField.Type
+			if embedded.Kind() == reflect.Ptr {
+				if typeField.PkgPath != "" {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			collectFields(embedded, typeField.Index, depth+1, selectorTag, visited, candidates)
+			continue
+		}
+		if typeField.PkgPath != "" {
+			continue
+		}
+		*candidates = append(*candidates, fieldCandidate{typeField: typeField, depth: depth})
+	}
+}
+
+// isEmbeddedStruct reports whether fields of the embedded struct should be promoted.
+// Embedded structs with a selector are parsed as ordinary nested structs.
+func isEmbeddedStruct(typeField reflect.StructField, selectorTag string) bool {
+	if !typeField.Anonymous {
+		return false
+	}
+	if _, has := typeField.Tag.Lookup(selectorTag); has {
+		return false
+	}
+	t := typeField.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isConverted(t) && !isUnmarshaler(t)
+}
+
+func (c *planCompiler) compileType(t reflect.Type) (*typePlan, error) {
+	switch {
+	case isConverted(t):
+		return &typePlan{kind: convertedKind, t: t}, nil
+	case isUnmarshaler(t):
+		return &typePlan{kind: unmarshalerKind, t: t}, nil
+	case isAttrsMap(t):
+		return &typePlan{kind: attrsKind, t: t}, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		elem, err := c.compileType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		kind := ptrKind
+		if t.Kind() == reflect.Slice {
+			kind = sliceKind
+		}
+		return &typePlan{kind: kind, t: t, elem: elem}, nil
+	case reflect.Struct:
+		return c.compileStruct(t)
+	}
+	return &typePlan{kind: scalarKind, t: t}, nil
+}
+
+func (c *planCompiler) compileField(typeField reflect.StructField) (*fieldPlan, error) {
+	tag := typeField.Tag
+	f := &fieldPlan{
+		name:     typeField.Name,
+		index:    typeField.Index,
+		selector: tag.Get(c.tags.selector),
+	}
+	if len(f.selector) > 0 {
+		var err error
+		if f.queries, err = compileSelector(c.tags.selector, f.selector); err != nil {
+			return nil, err
+		}
+	}
+
+	if label, has := tag.Lookup("label"); has {
+		var err error
+		if f.label, err = compileLabel(label); err != nil {
+			return nil, err
+		}
+	}
+
+	valueTag, hasValueTag := tag.Lookup(c.tags.value)
+	f.hasValueTag = hasValueTag
+	keyTag, hasKey := tag.Lookup("key")
+	if hasKey {
+		var err error
+		if f.key, err = compileKey(keyTag); err != nil {
+			return nil, err
+		}
+		var valueSelector string
+		valueSelector, valueTag = splitSelector(valueTag)
+		if valueSelector != "" {
+			if f.valueQueries, err = compileSelector(c.tags.value, valueSelector); err != nil {
+				return nil, err
+			}
+		}
+	}
+	f.count = valueTag == "count"
+	f.dataAttrs = valueTag == "data"
+	if f.count || f.dataAttrs {
+		valueTag = ""
+	}
+	sources, steps := splitValueTag(valueTag)
+	f.sources = sources
+	transforms, err := compileTransforms(steps)
+	if err != nil {
+		return nil, err
+	}
+	f.transforms = transforms
+	f.defaultValue, f.hasDefault = tag.Lookup("default")
+
+	if f.required, err = boolTag(typeField, "required"); err != nil {
+		return nil, err
+	}
+	if f.limits, err = countLimits(typeField); err != nil {
+		return nil, err
+	}
+	if number, has := tag.Lookup("number"); has {
+		f.numberFormat = NumberFormat(number)
+		if !isNumberFormat(f.numberFormat) {
+			return nil, fmt.Errorf("%w: number:%q", ErrInvalidTag, number)
+		}
+	}
+	if mode, has := tag.Lookup("bool"); has {
+		if mode != "present" {
+			return nil, fmt.Errorf("%w: bool:%q", ErrInvalidTag, mode)
+		}
+		f.boolPresent = true
+	}
+	if truthy, has := tag.Lookup("truthy"); has {
+		f.truthy = strings.Split(truthy, ",")
+		f.hasTruthy = true
+	}
+	if layout, has := tag.Lookup("layout"); has {
+		f.layouts = strings.Split(layout, "|")
+	}
+
+	if hasKey {
+		f.typ, err = c.compileMap(typeField.Type)
+	} else {
+		f.typ, err = c.compileType(typeField.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if follow, has := tag.Lookup("follow"); has {
+		if followedPlan(f.typ) == nil {
+			return nil, invalidFollow(typeField.Type)
+		}
+		if f.follow, err = compileFollow(follow); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// markRecursive marks fields which contain their own struct. They match descendants only,
+// otherwise the same node would be decoded endlessly, and follow a page once per call.
+// Fields without a selector or with a root selector are rejected, they would decode the same node again.
+func markRecursive(plan *typePlan, tags tagNames) error {
+	for _, field := range plan.fields {
+		if !reaches(field.typ, plan, map[*typePlan]bool{}) {
+			continue
+		}
+		if field.follow == nil && !selectsDescendants(field) {
+			err := fmt.Errorf("%w: recursive field %s should select descendants", ErrInvalidTag, field.typ.t)
+			return withField(err, field.name, tags.selector, field.selector)
+		}
+		field.recursive = true
+		field.queries = descendantQueries(field.queries)
+	}
+	return nil
+}
+
+// selectsDescendants reports whether the field selects nodes other than the current one and the root
+func selectsDescendants(field *fieldPlan) bool {
+	if field.label != "" {
+		return true
+	}
+	if len(field.queries) == 0 {
+		return false
+	}
+	for _, q := range field.queries {
+		if q.scope == rootScope {
+			return false
+		}
+	}
+	return true
+}
+
+// reaches reports whether a value of the type contains a value of the target struct
+func reaches(typ *typePlan, target *typePlan, visited map[*typePlan]bool) bool {
+	for typ.kind == ptrKind || typ.kind == sliceKind || typ.kind == mapKind {
+		typ = typ.elem
+	}
+	if typ.kind != structKind || visited[typ] {
+		return false
+	}
+	if typ == target {
+		return true
+	}
+	visited[typ] = true
+	for _, field := range typ.fields {
+		if reaches(field.typ, target, visited) {
+			return true
+		}
+	}
+	return false
+}
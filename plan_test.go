@@ -0,0 +1,242 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type InvalidPlan struct {
+	Info struct {
+		Count int `$:".count" min:"many"`
+	} `$:".info"`
+}
+
+func TestCompile(t *testing.T) {
+	assert.Nil(t, Compile(&Struct{}))
+	assert.Nil(t, Compile(Struct{}))
+	assert.True(t, errors.Is(Compile(""), ErrNotStruct))
+	assert.True(t, errors.Is(Compile(nil), ErrNotStruct))
+
+	err := Compile(&InvalidPlan{})
+	assert.True(t, errors.Is(err, ErrInvalidTag))
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Info.Count", fieldErr.Path)
+}
+
+type InvalidSelector struct {
+	Blank string `$:" "`
+}
+
+func TestCompileInvalidSelector(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&InvalidSelector{}), ErrInvalidTag))
+}
+
+type Tree struct {
+	Name  string `$:"span"`
+	Child *Tree  `$:"ul"`
+}
+
+func TestParseRecursive(t *testing.T) {
+	html := `
+	<html>
+	<body>
+		<span>root</span>
+		<ul>
+			<span>child</span>
+			<ul>
+				<span>grandchild</span>
+			</ul>
+		</ul>
+	</body>
+	`
+	tree := &Tree{}
+	err := Parse(html, tree)
+	assert.Nil(t, err)
+	assert.Equal(t, "root", tree.Name)
+	assert.Equal(t, "child", tree.Child.Name)
+	assert.Equal(t, "grandchild", tree.Child.Child.Name)
+	assert.Nil(t, tree.Child.Child.Child)
+}
+
+type Menu struct {
+	Items []MenuItem `$:"li"`
+}
+
+type MenuItem struct {
+	Name    string `$:"a"`
+	Submenu *Menu  `$:"ul"`
+}
+
+func TestParseMutuallyRecursive(t *testing.T) {
+	html := `
+	<html>
+	<body>
+		<ul>
+			<li><a>Docs</a><ul><li><a>API</a></li></ul></li>
+		</ul>
+	</body>
+	`
+	menu := &Menu{}
+	err := Parse(html, menu)
+	assert.Nil(t, err)
+	assert.Equal(t, "Docs", menu.Items[0].Name)
+	assert.Equal(t, "API", menu.Items[0].Submenu.Items[0].Name)
+}
+
+type UnselectedTree struct {
+	Name  string `$:"span"`
+	Child *UnselectedTree
+}
+
+type UnselectedForest struct {
+	Children []UnselectedForest
+}
+
+type UnselectedA struct {
+	B *UnselectedB
+}
+
+type UnselectedB struct {
+	A *UnselectedA `$:"div"`
+}
+
+type RootTree struct {
+	Child *RootTree `$:"/"`
+}
+
+type RootSelectorTree struct {
+	Child *RootSelectorTree `$:"/ ul"`
+}
+
+func TestCompileRecursiveWithoutSelector(t *testing.T) {
+	for _, v := range []interface{}{&UnselectedTree{}, &UnselectedForest{}, &UnselectedA{}, &RootTree{}, &RootSelectorTree{}} {
+		err := Compile(v)
+		assert.True(t, errors.Is(err, ErrInvalidTag), "%T: %v", v, err)
+	}
+	err := Parse(`<html><body><ul><li>1</li></ul></body></html>`, &UnselectedTree{})
+	assert.True(t, errors.Is(err, ErrInvalidTag))
+}
+
+func TestParseConcurrent(t *testing.T) {
+	html := `
+	<html>
+	<body>
+		<img src="img1.png"/>
+		<img src="img2.png"/>
+	</body>
+	`
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ic := &ImageCollection{}
+			err := Parse(html, ic)
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(ic.Images))
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkParse(b *testing.B) {
+	html := "<html><body>" + strings.Repeat(`<img src="img.png"/>`, 100) + "</body></html>"
+	for i := 0; i < b.N; i++ {
+		ic := &ImageCollection{}
+		if err := Parse(html, ic); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type PageMeta struct {
+	Title       string `$:"h1"`
+	Description string `$:".description"`
+}
+
+type PageInfo struct {
+	Lang string `$:".lang"`
+}
+
+type pageStats struct {
+	Views int `$:".views"`
+}
+
+type pageLinks struct {
+	Next string `$:"a"`
+}
+
+type Breadcrumbs struct {
+	Links []string `$:"a" value:"[href]"`
+}
+
+type FooterMeta struct {
+	Title string `$:"footer"`
+}
+
+type HeaderMeta struct {
+	Title string `$:"header"`
+}
+
+type ProductPage struct {
+	PageMeta
+	*PageInfo
+	pageStats
+	*pageLinks
+	Breadcrumbs `$:".breadcrumbs"`
+	Description string `$:".summary"`
+	Name        string `$:".name"`
+	Ignored     int    `$:"-"`
+	internal    int    `$:".name"`
+}
+
+type ConflictPage struct {
+	FooterMeta
+	HeaderMeta
+	*Breadcrumbs
+}
+
+const embeddedHtml = `
+	<html>
+	<body>
+		<header>Header</header>
+		<h1>Title</h1>
+		<p class="description">Description</p>
+		<p class="summary">Summary</p>
+		<span class="lang">en</span>
+		<span class="name">Name</span>
+		<span class="views">12</span>
+		<div class="breadcrumbs"><a href="/">Home</a></div>
+		<a href="/other">Other</a>
+		<footer>Footer</footer>
+	</body>
+	`
+
+func TestParseEmbedded(t *testing.T) {
+	p := &ProductPage{Ignored: 7}
+	err := Parse(embeddedHtml, p)
+	assert.Nil(t, err)
+	assert.Equal(t, "Title", p.Title)
+	assert.Equal(t, "", p.PageMeta.Description)
+	assert.Equal(t, "Summary", p.Description)
+	assert.Equal(t, "en", p.Lang)
+	assert.Equal(t, 12, p.Views)
+	assert.Nil(t, p.pageLinks)
+	assert.Equal(t, []string{"/"}, p.Links)
+	assert.Equal(t, "Name", p.Name)
+	assert.Equal(t, 7, p.Ignored)
+	assert.Equal(t, 0, p.internal)
+}
+
+func TestParseEmbeddedConflict(t *testing.T) {
+	p := &ConflictPage{}
+	err := Parse(embeddedHtml, p)
+	assert.Nil(t, err)
+	assert.Equal(t, "", p.FooterMeta.Title)
+	assert.Equal(t, "", p.HeaderMeta.Title)
+	assert.Equal(t, []string{"/", "/other"}, p.Links)
+}
@@ -0,0 +1,150 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"strings"
+)
+
+type queryScope int
+
+const (
+	nodeScope queryScope = iota
+	descendantScope
+	rootScope
+	ancestorScope
+	parentScope
+)
+
+// query is a compiled selector. By default the current node and its descendants are matched,
+// fields of recursive types match descendants only. Prefixes change the scope: "/ sel" matches
+// from the document root, "^ sel" matches ancestors starting from the closest one and "^^ sel"
+// matches from the parent. "/" and "^^" alone select the node itself.
+type query struct {
+	scope   queryScope
+	checker node.Checker
+}
+
+var queryPrefixes = []struct {
+	prefix string
+	scope  queryScope
+}{
+	{"^^", parentScope},
+	{"^", ancestorScope},
+	{"/", rootScope},
+}
+
+// compileSelector compiles selectors separated by || to queries tried in order
+func compileSelector(tag string, selector string) ([]query, error) {
+	var queries []query
+	for _, part := range strings.Split(selector, "||") {
+		q, ok := compileQuery(strings.TrimSpace(part))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s:%q", ErrInvalidTag, tag, selector)
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+func compileQuery(selector string) (query, bool) {
+	q := query{scope: nodeScope}
+	for _, p := range queryPrefixes {
+		if selector == p.prefix || strings.HasPrefix(selector, p.prefix+" ") {
+			q.scope = p.scope
+			selector = strings.TrimSpace(strings.TrimPrefix(selector, p.prefix))
+			break
+		}
+	}
+	if selector == "" {
+		return q, q.scope == rootScope || q.scope == parentScope
+	}
+	q.checker = node.CompileQuery(selector)
+	return q, q.checker != nil
+}
+
+// start returns the node to search from, or nil if there is no such node
+func (q query) start(n *node.Node) *node.Node {
+	switch q.scope {
+	case rootScope:
+		for n.Parent != nil {
+			n = n.Parent
+		}
+	case parentScope:
+		n = n.Parent
+	}
+	return n
+}
+
+func (q query) findAll(n *node.Node) []*node.Node {
+	if q.scope == ancestorScope {
+		var found []*node.Node
+		for ancestor := n.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if q.checker(ancestor) {
+				found = append(found, ancestor)
+			}
+		}
+		return found
+	}
+	start := q.start(n)
+	if start == nil {
+		return nil
+	}
+	if q.checker == nil {
+		return []*node.Node{start}
+	}
+	if q.scope == nodeScope {
+		return start.Filter(q.checker)
+	}
+	var found []*node.Node
+	for _, child := range start.Children {
+		found = append(found, child.Filter(q.checker)...)
+	}
+	return found
+}
+
+func (q query) findOne(n *node.Node) *node.Node {
+	if q.scope == ancestorScope {
+		for ancestor := n.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if q.checker(ancestor) {
+				return ancestor
+			}
+		}
+		return nil
+	}
+	start := q.start(n)
+	if start == nil || q.checker == nil {
+		return start
+	}
+	if q.scope == nodeScope {
+		return start.Find(q.checker)
+	}
+	for _, child := range start.Children {
+		if found := child.Find(q.checker); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// descendantQueries returns the queries which don't match the current node, so a field of a recursive type
+// doesn't decode the same node again
+func descendantQueries(queries []query) []query {
+	result := make([]query, len(queries))
+	for i, q := range queries {
+		if q.scope == nodeScope {
+			q.scope = descendantScope
+		}
+		result[i] = q
+	}
+	return result
+}
+
+func findFirst(n *node.Node, queries []query) *node.Node {
+	for _, q := range queries {
+		if found := q.findOne(n); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Fallback struct {
+	Price  string   `$:".price-new || .price || [itemprop=price]"`
+	Tags   []string `$:".tag-new || .tag"`
+	Absent *string  `$:".absent || .missing"`
+}
+
+func TestParseFallbackSelectors(t *testing.T) {
+	html := `
+	<body>
+		<span itemprop="price">3</span>
+		<span class="price">2</span>
+		<span class="price-new">1</span>
+		<span class="tag">a</span>
+		<span class="tag">b</span>
+	</body>
+	`
+	fallback := &Fallback{}
+	assert.Nil(t, Parse(html, fallback))
+	assert.Equal(t, "1", fallback.Price)
+	assert.Equal(t, []string{"a", "b"}, fallback.Tags)
+	assert.Nil(t, fallback.Absent)
+
+	assert.Nil(t, Parse(`<body><span itemprop="price">3</span><span class="price">2</span></body>`, fallback))
+	assert.Equal(t, "2", fallback.Price)
+}
+
+type FallbackInvalid struct {
+	Price string `$:".price || "`
+}
+
+func TestCompileFallbackSelectors(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&FallbackInvalid{}), ErrInvalidTag))
+}
+
+type ScopedItem struct {
+	Name     string   `$:".name"`
+	Currency string   `$:"/ .currency"`
+	Title    string   `$:"/ title"`
+	Category string   `$:"^ section" value:"[data-category]"`
+	Sections []string `$:"^ section" value:"[data-category]"`
+	List     string   `$:"^^" value:"[id]"`
+	Siblings int      `$:"^^ .item" value:"count"`
+	Position string   `$:"^ .missing || ^^" value:"[data-position]"`
+}
+
+type ScopedPage struct {
+	Items []ScopedItem `$:".item"`
+	Root  string       `$:"/" value:"tag"`
+}
+
+func TestParseScopedSelectors(t *testing.T) {
+	html := `
+	<html>
+	<head><title>Shop</title></head>
+	<body>
+		<span class="currency">EUR</span>
+		<section data-category="outer">
+			<section data-category="inner">
+				<ul id="list" data-position="top">
+					<li class="item"><span class="name">A</span></li>
+					<li class="item"><span class="name">B</span></li>
+				</ul>
+			</section>
+		</section>
+	</body>
+	</html>
+	`
+	page := &ScopedPage{}
+	assert.Nil(t, Parse(html, page))
+	assert.Equal(t, "html", page.Root)
+	assert.Equal(t, 2, len(page.Items))
+	item := page.Items[1]
+	assert.Equal(t, "B", item.Name)
+	assert.Equal(t, "EUR", item.Currency)
+	assert.Equal(t, "Shop", item.Title)
+	assert.Equal(t, "inner", item.Category)
+	assert.Equal(t, []string{"inner", "outer"}, item.Sections)
+	assert.Equal(t, "list", item.List)
+	assert.Equal(t, 2, item.Siblings)
+	assert.Equal(t, "top", item.Position)
+}
+
+type ScopeInvalid struct {
+	Ancestor string `$:"^"`
+}
+
+func TestCompileQuery(t *testing.T) {
+	assert.True(t, errors.Is(Compile(&ScopeInvalid{}), ErrInvalidTag))
+
+	q, ok := compileQuery("^^ .item")
+	assert.True(t, ok)
+	assert.Equal(t, parentScope, q.scope)
+	q, ok = compileQuery("/")
+	assert.True(t, ok)
+	assert.Equal(t, rootScope, q.scope)
+	assert.Nil(t, q.checker)
+	q, ok = compileQuery("a[href^=http]")
+	assert.True(t, ok)
+	assert.Equal(t, nodeScope, q.scope)
+}
@@ -0,0 +1,102 @@
+package gordom
+
+import (
+	"fmt"
+	"github.com/lucky-libora/gordom/node"
+	"io"
+	"net/url"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// itemType returns T of the callback func(item *T) error
+func itemType(fn interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 || t.Out(0) != errorType {
+		return nil, ErrNotFunc
+	}
+	in := t.In(0)
+	if in.Kind() != reflect.Ptr || in.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotFunc
+	}
+	return in.Elem(), nil
+}
+
+// DecodeEach parses html and calls fn of type func(item *T) error for every node matching the selector.
+// Nodes are decoded as soon as they are parsed and aren't kept in memory after fn returns,
+// so large lists can be handled with bounded memory. An error returned by fn stops decoding and is returned as is.
+// In lenient mode, fn gets partially filled items and FieldErrors of all items is returned at the end.
+func (d *Decoder) DecodeEach(reader io.Reader, selector string, fn interface{}) error {
+	t, err := itemType(fn)
+	if err != nil {
+		return err
+	}
+	checker := node.CompileQuery(selector)
+	if checker == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidSelector, selector)
+	}
+	plan, err := structPlanOf(t, d.opts.tags)
+	if err != nil {
+		return err
+	}
+	reader, err = d.utf8Reader(reader, "")
+	if err != nil {
+		return err
+	}
+	callback := reflect.ValueOf(fn)
+	pageURL, err := d.optionURL()
+	if err != nil {
+		return err
+	}
+	p := d.newParser()
+	var errs FieldErrors
+	i := 0
+	err = node.ParseHtmlEach(reader, checker, func(n *node.Node) error {
+		if i == 0 {
+			p.base = streamBase(n, pageURL)
+		}
+		if d.opts.absoluteURLs {
+			n.ResolveURLs(p.base)
+		}
+		item := reflect.New(t)
+		_, err := p.setFields(n, item.Elem(), plan)
+		if err != nil {
+			if !p.opts.lenient {
+				return withIndex(err, i)
+			}
+			errs = appendErrors(errs, withIndex(err, i))
+		}
+		i++
+		if out := callback.Call([]reflect.Value{item})[0]; !out.IsNil() {
+			return out.Interface().(error)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// streamBase returns the base url of the streamed document. <head> is parsed before matched nodes,
+// so <base href> is already known.
+func streamBase(n *node.Node, pageURL *url.URL) *url.URL {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	head := root.Find(func(n *node.Node) bool {
+		return n.Tag == "head"
+	})
+	doc := &node.Document{Head: head, URL: pageURL}
+	return doc.BaseURL()
+}
+
+// DecodeEach calls fn of type func(item *T) error for every node matching the selector, see Decoder.DecodeEach
+func DecodeEach(reader io.Reader, selector string, fn interface{}, opts ...Option) error {
+	return decoderOf(opts).DecodeEach(reader, selector, fn)
+}
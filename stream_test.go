@@ -0,0 +1,95 @@
+package gordom
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type Row struct {
+	Id       int    `$:"td.id"`
+	Name     string `$:"td.name"`
+	Currency string `$:"/ .currency"`
+}
+
+func rowsHtml(n int) string {
+	b := &strings.Builder{}
+	b.WriteString(`<html><body><span class="currency">EUR</span><table>`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(b, `<tr class="row"><td class="id">%d</td><td class="name">row %d</td></tr>`, i, i)
+	}
+	b.WriteString(`</table></body></html>`)
+	return b.String()
+}
+
+func TestDecodeEach(t *testing.T) {
+	var rows []Row
+	err := DecodeEach(strings.NewReader(rowsHtml(100)), "tr.row", func(row *Row) error {
+		rows = append(rows, *row)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 100, len(rows))
+	assert.Equal(t, Row{Id: 42, Name: "row 42", Currency: "EUR"}, rows[42])
+}
+
+func TestDecodeEachPseudoClass(t *testing.T) {
+	for _, selector := range []string{"tr:first-child", "table > tr:last-child"} {
+		count := 0
+		err := DecodeEach(strings.NewReader(rowsHtml(3)), selector, func(row *Row) error {
+			count++
+			return nil
+		})
+		assert.Nil(t, err, selector)
+		assert.Equal(t, 3, count, selector)
+	}
+}
+
+func TestDecodeEachStop(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := DecodeEach(strings.NewReader(rowsHtml(100)), "tr.row", func(row *Row) error {
+		count++
+		if row.Id == 9 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 10, count)
+}
+
+func TestDecodeEachFieldError(t *testing.T) {
+	html := `<body><p><i>1</i></p><p><i>x</i></p><p><i>3</i></p></body>`
+	type Item struct {
+		Value int `$:"i"`
+	}
+	count := 0
+	err := DecodeEach(strings.NewReader(html), "p", func(item *Item) error {
+		count++
+		return nil
+	})
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "[1].Value", fieldErr.Path)
+	assert.Equal(t, 1, count)
+
+	var values []int
+	err = DecodeEach(strings.NewReader(html), "p", func(item *Item) error {
+		values = append(values, item.Value)
+		return nil
+	}, WithLenient())
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, []int{1, 0, 3}, values)
+}
+
+func TestDecodeEachInvalid(t *testing.T) {
+	reader := strings.NewReader(rowsHtml(1))
+	assert.True(t, errors.Is(DecodeEach(reader, "tr", nil), ErrNotFunc))
+	assert.True(t, errors.Is(DecodeEach(reader, "tr", func(row Row) error { return nil }), ErrNotFunc))
+	assert.True(t, errors.Is(DecodeEach(reader, "tr", func(row *Row) {}), ErrNotFunc))
+	assert.True(t, errors.Is(DecodeEach(reader, "tr", func(s *string) error { return nil }), ErrNotFunc))
+	assert.True(t, errors.Is(DecodeEach(reader, " ", func(row *Row) error { return nil }), ErrInvalidSelector))
+}
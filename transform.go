@@ -0,0 +1,142 @@
+package gordom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Transform changes a value extracted from a node.
+// arg is a part of the pipeline step after the colon: "regex:(\d+)" passes "(\d+)".
+type Transform func(value string, arg string) (string, error)
+
+var (
+	transformsMutex = sync.RWMutex{}
+	transforms      = map[string]Transform{
+		"lower":      lowerTransform,
+		"regex":      regexTransform,
+		"replace":    replaceTransform,
+		"trim":       trimTransform,
+		"trimprefix": trimPrefixTransform,
+		"trimsuffix": trimSuffixTransform,
+		"upper":      upperTransform,
+	}
+	regexCache = sync.Map{}
+)
+
+// RegisterTransform makes transform available in value tags by the name.
+// It replaces a transform with the same name, including the built-in ones.
+// Transforms are bound to structs when they are compiled, so they should be registered before parsing.
+func RegisterTransform(name string, transform Transform) {
+	transformsMutex.Lock()
+	defer transformsMutex.Unlock()
+	transforms[name] = transform
+}
+
+type transformStep struct {
+	transform Transform
+	arg       string
+}
+
+func applyTransforms(value string, steps []transformStep) (string, error) {
+	for _, step := range steps {
+		var err error
+		value, err = step.transform(value, step.arg)
+		if err != nil {
+			return value, err
+		}
+	}
+	return value, nil
+}
+
+func compileTransforms(steps []string) ([]transformStep, error) {
+	compiled := make([]transformStep, 0, len(steps))
+	for _, step := range steps {
+		transform, arg, err := lookupTransform(step)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(step, "regex:") {
+			if _, err := compileRegex(arg); err != nil {
+				return nil, err
+			}
+		}
+		compiled = append(compiled, transformStep{transform: transform, arg: arg})
+	}
+	return compiled, nil
+}
+
+func lookupTransform(step string) (Transform, string, error) {
+	name := step
+	arg := ""
+	if i := strings.Index(step, ":"); i >= 0 {
+		name = step[:i]
+		arg = step[i+1:]
+	}
+	transformsMutex.RLock()
+	transform, has := transforms[name]
+	transformsMutex.RUnlock()
+	if !has {
+		return nil, "", fmt.Errorf("%w: unknown transform %q", ErrInvalidTag, name)
+	}
+	return transform, arg, nil
+}
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if cached, has := regexCache.Load(expr); has {
+		return cached.(*regexp.Regexp), nil
+	}
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+	}
+	regexCache.Store(expr, regex)
+	return regex, nil
+}
+
+func lowerTransform(value string, arg string) (string, error) {
+	return strings.ToLower(value), nil
+}
+
+func regexTransform(value string, arg string) (string, error) {
+	regex, err := compileRegex(arg)
+	if err != nil {
+		return value, err
+	}
+	match := regex.FindStringSubmatch(value)
+	if len(match) == 0 {
+		return "", nil
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+func replaceTransform(value string, arg string) (string, error) {
+	i := strings.Index(arg, ":")
+	if i < 0 {
+		return strings.ReplaceAll(value, arg, ""), nil
+	}
+	return strings.ReplaceAll(value, arg[:i], arg[i+1:]), nil
+}
+
+func trimTransform(value string, arg string) (string, error) {
+	if arg == "" {
+		return strings.TrimSpace(value), nil
+	}
+	return strings.Trim(value, arg), nil
+}
+
+func trimPrefixTransform(value string, arg string) (string, error) {
+	return strings.TrimPrefix(value, arg), nil
+}
+
+func trimSuffixTransform(value string, arg string) (string, error) {
+	return strings.TrimSuffix(value, arg), nil
+}
+
+func upperTransform(value string, arg string) (string, error) {
+	return strings.ToUpper(value), nil
+}
@@ -0,0 +1,99 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const transformHtml = `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<a href=" /About ">About</a>
+		<span class="commits">1,234 commits</span>
+		<img class="lazy" data-src="lazy.png" src="placeholder.png"/>
+		<img class="eager" src="eager.png"/>
+		<span class="sku">SKU: a|b</span>
+	</body>
+	`
+
+type Transforms struct {
+	Link     string `$:"a" value:"[href]|trim|lower"`
+	Commits  int    `$:".commits" value:"text|replace:,:|regex:(\\d+)"`
+	Lazy     string `$:".lazy" value:"[data-src]|[src]"`
+	Eager    string `$:".eager" value:"[data-src]|[src]"`
+	Upper    string `$:"a" value:"upper"`
+	Sku      string `$:".sku" value:"trimprefix:SKU: |replace:\\|:/"`
+	NoMatch  string `$:"a" value:"regex:\\d+" default:"none"`
+	Reversed string `$:"a" value:"reverse"`
+}
+
+func reverseTransform(value string, arg string) (string, error) {
+	runes := []rune(value)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+func TestParseTransforms(t *testing.T) {
+	RegisterTransform("reverse", reverseTransform)
+	tr := &Transforms{}
+	err := Parse(transformHtml, tr)
+	assert.Nil(t, err)
+	assert.Equal(t, "/about", tr.Link)
+	assert.Equal(t, 1234, tr.Commits)
+	assert.Equal(t, "lazy.png", tr.Lazy)
+	assert.Equal(t, "eager.png", tr.Eager)
+	assert.Equal(t, "ABOUT", tr.Upper)
+	assert.Equal(t, "a/b", tr.Sku)
+	assert.Equal(t, "none", tr.NoMatch)
+	assert.Equal(t, "tuobA", tr.Reversed)
+}
+
+type UnknownTransform struct {
+	Link string `$:"a" value:"[href]|unknown"`
+}
+
+type InvalidRegex struct {
+	Link string `$:"a" value:"regex:("`
+}
+
+func TestParseTransformErrors(t *testing.T) {
+	assert.True(t, errors.Is(Parse(transformHtml, &UnknownTransform{}), ErrInvalidTag))
+	assert.True(t, errors.Is(Parse(transformHtml, &InvalidRegex{}), ErrInvalidTag))
+}
+
+var errTooLong = errors.New("too long")
+
+type FailingTransform struct {
+	Text string `$:".commits" value:"maxlen"`
+}
+
+func TestParseTransformError(t *testing.T) {
+	RegisterTransform("maxlen", func(value string, arg string) (string, error) {
+		if len(strings.Fields(value)) > 1 {
+			return value, errTooLong
+		}
+		return value, nil
+	})
+	err := Parse(transformHtml, &FailingTransform{})
+	assert.True(t, errors.Is(err, errTooLong))
+	fieldErr := &FieldError{}
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Text", fieldErr.Path)
+	assert.Equal(t, "1,234 commits", fieldErr.Value)
+}
+
+func TestSplitValueTag(t *testing.T) {
+	sources, transforms := splitValueTag(`[data-src]|[src]|trim|regex:a\|b`)
+	assert.Equal(t, []string{"[data-src]", "[src]"}, sources)
+	assert.Equal(t, []string{"trim", "regex:a|b"}, transforms)
+	sources, transforms = splitValueTag("")
+	assert.Equal(t, []string{"text"}, sources)
+	assert.Empty(t, transforms)
+}
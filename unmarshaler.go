@@ -0,0 +1,54 @@
+package gordom
+
+import (
+	"encoding"
+	"github.com/lucky-libora/gordom/node"
+	"reflect"
+)
+
+// Unmarshaler is implemented by types that can decode themselves from a found html node.
+// Types implementing encoding.TextUnmarshaler are decoded from the field value instead.
+type Unmarshaler interface {
+	UnmarshalHTML(n *node.Node) error
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isUnmarshaler(t reflect.Type) bool {
+	ptrType := reflect.PtrTo(t)
+	return ptrType.Implements(unmarshalerType) || ptrType.Implements(textUnmarshalerType)
+}
+
+func (p *parser) parseUnmarshaler(node *node.Node, field *fieldPlan, valueField reflect.Value) error {
+	found, err := findChecked(node, field)
+	if err != nil || found == nil {
+		return err
+	}
+	_, err = p.unmarshal(found, field, valueField)
+	return err
+}
+
+func (p *parser) unmarshal(node *node.Node, field *fieldPlan, valueField reflect.Value) (bool, error) {
+	if !valueField.CanAddr() {
+		return false, nil
+	}
+	switch u := valueField.Addr().Interface().(type) {
+	case Unmarshaler:
+		err := u.UnmarshalHTML(node)
+		if err != nil {
+			value, _ := p.getValue(node, field)
+			return true, newValueError(value, err)
+		}
+		return true, nil
+	case encoding.TextUnmarshaler:
+		value, err := p.getValue(node, field)
+		if err != nil {
+			return true, err
+		}
+		return true, newValueError(value, u.UnmarshalText([]byte(value)))
+	}
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package gordom
+
+import (
+	"errors"
+	"github.com/lucky-libora/gordom/node"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Money struct {
+	Amount   string
+	Currency string
+}
+
+func (m *Money) UnmarshalHTML(n *node.Node) error {
+	m.Currency = n.Attrs["data-currency"]
+	m.Amount = n.InnerText()
+	return nil
+}
+
+type Rating int
+
+func (r *Rating) UnmarshalText(text []byte) error {
+	stars := strings.TrimSuffix(string(text), " stars")
+	i, err := strconv.Atoi(stars)
+	if err != nil {
+		return err
+	}
+	*r = Rating(i)
+	return nil
+}
+
+type Product struct {
+	Price     Money    `$:".price"`
+	OldPrice  *Money   `$:".old-price"`
+	NoPrice   *Money   `$:".no-price"`
+	Rating    Rating   `$:".rating"`
+	Ratings   []Rating `$:".review" value:"[data-rating]"`
+	NoRating  Rating   `$:".no-rating"`
+	AllPrices []Money  `$:".price, .old-price"`
+}
+
+func TestParseUnmarshaler(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		<span class="price" data-currency="USD">10</span>
+		<span class="old-price" data-currency="EUR">12</span>
+		<span class="rating">4 stars</span>
+		<span class="review" data-rating="5 stars"></span>
+		<span class="review" data-rating="3 stars"></span>
+	</body>
+	`
+	p := &Product{}
+	err := Parse(html, p)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: "10", Currency: "USD"}, p.Price)
+	assert.Equal(t, &Money{Amount: "12", Currency: "EUR"}, p.OldPrice)
+	assert.Nil(t, p.NoPrice)
+	assert.Equal(t, Rating(4), p.Rating)
+	assert.Equal(t, []Rating{5, 3}, p.Ratings)
+	assert.Equal(t, Rating(0), p.NoRating)
+	assert.Equal(t, 2, len(p.AllPrices))
+}
+
+type Broken struct{}
+
+var errBroken = errors.New("broken")
+
+func (b *Broken) UnmarshalHTML(n *node.Node) error {
+	return errBroken
+}
+
+type BrokenField struct {
+	Broken Broken
+}
+
+func TestParseUnmarshalerError(t *testing.T) {
+	html := `
+	<html>
+	<head>
+		<meta>
+	</head>
+	<body>
+		Test
+	</body>
+	`
+	err := Parse(html, &BrokenField{})
+	assert.True(t, errors.Is(err, errBroken))
+}
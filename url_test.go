@@ -0,0 +1,134 @@
+package gordom
+
+import (
+	"github.com/lucky-libora/gordom/node"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type Gallery struct {
+	Link   string   `$:"a" value:"url[href]"`
+	Raw    string   `$:"a" value:"[href]"`
+	Images []string `$:"img" value:"url[data-src]|url[src]"`
+	Empty  string   `$:"a" value:"url[title]" default:"none"`
+}
+
+const galleryHtml = `<html><body>
+<a href="../about#team">about</a>
+<img src="img/1.png"><img data-src="/img/2.png" src="blank.png"><img src="https://cdn.com/3.png">
+</body></html>`
+
+func TestParseURLValue(t *testing.T) {
+	gallery := &Gallery{}
+	err := Parse(galleryHtml, gallery, WithURL("https://example.com/gallery/index.html"))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Link)
+	assert.Equal(t, "../about#team", gallery.Raw)
+	assert.Equal(t, []string{
+		"https://example.com/gallery/img/1.png",
+		"https://example.com/img/2.png",
+		"https://cdn.com/3.png",
+	}, gallery.Images)
+	assert.Equal(t, "none", gallery.Empty)
+}
+
+func TestParseURLValueWithoutURL(t *testing.T) {
+	gallery := &Gallery{}
+	err := Parse(galleryHtml, gallery)
+	assert.Nil(t, err)
+	assert.Equal(t, "../about#team", gallery.Link)
+
+	html := `<html><head><base href="https://example.com/a/"></head>` + galleryHtml[len("<html>"):]
+	err = Parse(html, gallery)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Link)
+}
+
+func TestParseURLValueInvalidURL(t *testing.T) {
+	err := Parse(galleryHtml, &Gallery{}, WithURL("http://[::1"))
+	assert.Error(t, err)
+}
+
+func TestParseAbsoluteURLs(t *testing.T) {
+	gallery := &Gallery{}
+	err := Parse(galleryHtml, gallery, WithURL("https://example.com/gallery/"), WithAbsoluteURLs())
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Raw)
+}
+
+func TestParseDocumentURL(t *testing.T) {
+	doc := node.ParseHtml(strings.NewReader(galleryHtml))
+	doc.URL, _ = url.Parse("https://example.com/gallery/")
+	gallery := &Gallery{}
+	err := ParseDocument(doc, gallery, WithURL("https://other.com/"), WithAbsoluteURLs())
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Link)
+	assert.Equal(t, "https://example.com/about#team", doc.SelectOne("a").Attrs["href"])
+}
+
+func TestParseDocumentWithURL(t *testing.T) {
+	doc := node.ParseHtml(strings.NewReader(galleryHtml))
+	first := &Gallery{}
+	assert.Nil(t, ParseDocument(doc, first, WithURL("https://example.com/gallery/")))
+	assert.Equal(t, "https://example.com/about#team", first.Link)
+	assert.Nil(t, doc.URL)
+
+	second := &Gallery{}
+	assert.Nil(t, ParseDocument(doc, second, WithURL("https://other.com/a/b")))
+	assert.Equal(t, "https://other.com/about#team", second.Link)
+	assert.Equal(t, "../about#team", second.Raw)
+}
+
+func TestDecodeEachURL(t *testing.T) {
+	html := `<html><head><base href="/a/"></head><body>
+	<li><a href="1">1</a></li><li><a href="2">2</a></li>
+	</body></html>`
+	var links []string
+	err := DecodeEach(strings.NewReader(html), "li", func(gallery *Gallery) error {
+		links = append(links, gallery.Link, gallery.Raw)
+		return nil
+	}, WithURL("https://example.com/x/"), WithAbsoluteURLs())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"https://example.com/a/1", "https://example.com/a/1",
+		"https://example.com/a/2", "https://example.com/a/2",
+	}, links)
+}
+
+func TestParseNodeURL(t *testing.T) {
+	doc := node.ParseHtml(strings.NewReader(galleryHtml))
+	gallery := &Gallery{}
+	err := ParseNode(doc.Body, gallery, WithURL("https://example.com/gallery/"))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Link)
+}
+
+func TestParseNodeAbsoluteURLs(t *testing.T) {
+	doc := node.ParseHtml(strings.NewReader(galleryHtml))
+	gallery := &Gallery{}
+	err := ParseNode(doc.Body, gallery, WithURL("https://example.com/gallery/"), WithAbsoluteURLs())
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/about#team", gallery.Raw)
+	assert.Equal(t, "https://example.com/gallery/img/1.png", doc.SelectOne("img").Attrs["src"])
+}
+
+func TestParseFromURLContextURLValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/gallery/", http.StatusFound)
+			return
+		}
+		w.Write([]byte(galleryHtml))
+	}))
+	defer server.Close()
+
+	gallery := &Gallery{}
+	err := ParseFromUrl(server.URL+"/old", gallery, WithURL("https://example.com/"))
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/about#team", gallery.Link)
+	assert.Equal(t, server.URL+"/gallery/img/1.png", gallery.Images[0])
+}
@@ -0,0 +1,165 @@
+package gordom
+
+import (
+	"github.com/lucky-libora/gordom/node"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+var valueSources = []string{"text", "owntext", "html", "outerhtml", "tag"}
+
+func defaultNode(field *fieldPlan) *node.Node {
+	if !field.hasDefault {
+		return nil
+	}
+	n := node.NewNode("", nil)
+	n.Text = field.defaultValue
+	return n
+}
+
+func (p *parser) getValue(n *node.Node, field *fieldPlan) (string, error) {
+	value := ""
+	if n != nil {
+		raw := extractValue(n, field.sources, p.base)
+		if p.opts.normalizeText != nil {
+			raw = p.opts.normalizeText(raw)
+		}
+		var err error
+		value, err = applyTransforms(raw, field.transforms)
+		if err != nil {
+			return value, newValueError(raw, err)
+		}
+	}
+	if value == "" {
+		return field.defaultValue, nil
+	}
+	return value, nil
+}
+
+// NormalizeSpace trims the value and replaces sequences of white space, including non-breaking spaces, with a single space
+func NormalizeSpace(value string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(value, "\u00a0", " ")), " ")
+}
+
+func extractValue(n *node.Node, sources []string, base *url.URL) string {
+	for _, source := range sources {
+		value := extractSource(n, source, base)
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func extractSource(n *node.Node, source string, base *url.URL) string {
+	if isAttrSource(source) {
+		attrKey := strings.Trim(source, "[]")
+		return n.Attrs[attrKey]
+	}
+	if isURLSource(source) {
+		attrKey := strings.Trim(strings.TrimPrefix(source, "url"), "[]")
+		// an empty value isn't resolved to the base url, so the default value can be used
+		if value := n.Attrs[attrKey]; value != "" {
+			return node.ResolveURL(base, value)
+		}
+		return ""
+	}
+	switch source {
+	case "html":
+		return n.InnerHTML()
+	case "outerhtml":
+		return n.OuterHTML()
+	case "owntext":
+		return n.OwnText()
+	case "tag":
+		return n.Tag
+	}
+	return n.InnerText()
+}
+
+func isAttrSource(source string) bool {
+	return strings.HasPrefix(source, "[") && strings.HasSuffix(source, "]")
+}
+
+// isURLSource reports whether the source is like url[href], which resolves the url in the attribute
+func isURLSource(source string) bool {
+	return strings.HasPrefix(source, "url[") && strings.HasSuffix(source, "]")
+}
+
+func isValueSource(source string) bool {
+	if isAttrSource(source) || isURLSource(source) {
+		return true
+	}
+	for _, s := range valueSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// splitValueTag splits value tag like "[data-src]|[src]|trim" to sources and transforms.
+// The pipe can be escaped by backslash inside transform arguments.
+func splitValueTag(tag string) ([]string, []string) {
+	steps := splitPipeline(tag)
+	i := 0
+	for i < len(steps) && isValueSource(steps[i]) {
+		i++
+	}
+	sources := steps[:i]
+	if len(sources) == 0 {
+		sources = []string{"text"}
+	}
+	return sources, steps[i:]
+}
+
+func splitPipeline(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	steps := []string{}
+	step := ""
+	escaped := false
+	for _, ch := range tag {
+		if escaped {
+			if ch != '|' {
+				step += "\\"
+			}
+			step += string(ch)
+			escaped = false
+			continue
+		}
+		switch ch {
+		case '\\':
+			escaped = true
+		case '|':
+			steps = append(steps, step)
+			step = ""
+		default:
+			step += string(ch)
+		}
+	}
+	if escaped {
+		step += "\\"
+	}
+	return append(steps, step)
+}
+
+func getAttrs(n *node.Node, field *fieldPlan, t reflect.Type) reflect.Value {
+	attrs := reflect.MakeMap(t)
+	for key, value := range n.Attrs {
+		if field.dataAttrs {
+			if !strings.HasPrefix(key, "data-") {
+				continue
+			}
+			key = strings.TrimPrefix(key, "data-")
+		}
+		attrs.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), reflect.ValueOf(value).Convert(t.Elem()))
+	}
+	return attrs
+}
+
+func isAttrsMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+}